@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modern-devops/xvm/mirrors"
+	"github.com/modern-devops/xvm/sdks"
+	"github.com/modern-devops/xvm/tools/binpath"
+	"github.com/modern-devops/xvm/tools/commander"
+	"github.com/modern-devops/xvm/tools/linker"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+const mirrorTimeout = 5 * time.Second
+
+type severity int
+
+const (
+	ok severity = iota
+	warning
+	problem
+)
+
+// finding is the result of a diagnostic, fix suggests how to solve it unless it is ok
+type finding struct {
+	severity severity
+	message  string
+	fix      string
+}
+
+var subCommandDoctor = &cobra.Command{
+	Use:           "doctor",
+	Short:         "Diagnose the environment of xvm and suggest how to fix the problems found",
+	Example:       "Check whether the activated sdks are used from env.PATH: `xvm doctor`",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+		cfg := &config{path: installer.ConfigPath}
+		if err := cfg.load(); err != nil {
+			return err
+		}
+		var findings []*finding
+		findings = append(findings, checkXvm()...)
+		findings = append(findings, checkShims(installer, cfg)...)
+		findings = append(findings, checkPath(installer, cfg)...)
+		findings = append(findings, checkRcFile(installer, cfg)...)
+		findings = append(findings, checkMirrors(installer)...)
+		findings = append(findings, checkInstallations(installer)...)
+		problems, warnings := 0, 0
+		for _, f := range findings {
+			switch f.severity {
+			case ok:
+				log.Info().Msgf("[ok] %s", f.message)
+				continue
+			case warning:
+				warnings++
+				log.Warn().Msgf("[warning] %s", f.message)
+			case problem:
+				problems++
+				log.Error().Msgf("[problem] %s", f.message)
+			}
+			log.Info().Msgf("    fix: %s", f.fix)
+		}
+		if problems > 0 {
+			return fmt.Errorf("%d problems and %d warnings are found, fix them as suggested above", problems, warnings)
+		}
+		log.Info().Msgf("No problems and %d warnings are found", warnings)
+		return nil
+	},
+}
+
+// checkXvm checks whether the xvm called by the shims is found in env.PATH and is the running one
+func checkXvm() []*finding {
+	exe, err := os.Executable()
+	if err != nil {
+		return []*finding{{problem, fmt.Sprintf("Failed to locate the running %s: %s", app, err), "reinstall xvm"}}
+	}
+	exe = realPath(exe)
+	path, err := exec.LookPath(app)
+	if err != nil {
+		return []*finding{{problem, fmt.Sprintf("the %s command cannot be found in env.PATH", app),
+			fmt.Sprintf("add [%s] to env.PATH, or move %s into a directory of env.PATH such as /usr/local/bin", filepath.Dir(exe), exe)}}
+	}
+	if path = realPath(path); path != exe {
+		return []*finding{{warning, fmt.Sprintf("the %s command found in env.PATH is %s, but the running one is %s", app, path, exe),
+			fmt.Sprintf("remove the stale %s, or replace it with %s", path, exe)}}
+	}
+	return []*finding{{ok, fmt.Sprintf("the %s command is found at %s", app, path), ""}}
+}
+
+// checkShims checks whether the files linked for the tools of the activated sdks call a valid xvm
+func checkShims(installer *sdks.UserIsolatedInstaller, cfg *config) []*finding {
+	if len(cfg.Sdks) == 0 {
+		return []*finding{{warning, "no sdks are activated", "run `xvm activate -a --add_binpath` to activate all sdks"}}
+	}
+	var findings []*finding
+	for _, name := range cfg.Sdks {
+		sdk, err := installer.GetSdk(name)
+		if err != nil {
+			findings = append(findings, &finding{warning, fmt.Sprintf("the activated sdk %s is unknown", name),
+				fmt.Sprintf("remove it from the sdks of %s, or install the plugin providing it", installer.ConfigPath)})
+			continue
+		}
+		for _, tool := range sdk.Info().Tools {
+			findings = append(findings, checkShim(installer, name, tool.Name))
+		}
+	}
+	return findings
+}
+
+func checkShim(installer *sdks.UserIsolatedInstaller, sdk, tool string) *finding {
+	fix := fmt.Sprintf("run `xvm activate %s` to link it again", sdk)
+	file, command, err := linker.Command(tool, installer.BinPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &finding{problem, fmt.Sprintf("the %s command is not linked at %s", tool, file), fix}
+	}
+	if err != nil {
+		return &finding{problem, err.Error(), fix}
+	}
+	args := commander.Parse(command)
+	if len(args) != 3 || args[1] != "exec" || args[2] != tool {
+		return &finding{problem, fmt.Sprintf("%s calls `%s` rather than `xvm exec %s`", file, command, tool), fix}
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return &finding{problem, fmt.Sprintf("%s calls %s, which cannot be found", file, args[0]),
+			fmt.Sprintf("make sure the %s command is found in env.PATH", app)}
+	}
+	return &finding{ok, fmt.Sprintf("the %s command is linked at %s", tool, file), ""}
+}
+
+// checkPath checks whether the tools of the activated sdks are found in the binary path of xvm
+// first, rather than shadowed by the system ones found in an earlier directory of env.PATH
+func checkPath(installer *sdks.UserIsolatedInstaller, cfg *config) []*finding {
+	dirs := filepath.SplitList(os.Getenv("PATH"))
+	index := slices.IndexFunc(dirs, func(dir string) bool {
+		return dir != "" && realPath(dir) == realPath(installer.BinPath)
+	})
+	if index == -1 {
+		return []*finding{{problem, fmt.Sprintf("[%s] is not in env.PATH", installer.BinPath),
+			"run `xvm activate --add_binpath` and open a new terminal"}}
+	}
+	findings := []*finding{{ok, fmt.Sprintf("[%s] is in env.PATH", installer.BinPath), ""}}
+	for _, name := range cfg.Sdks {
+		sdk, err := installer.GetSdk(name)
+		if err != nil {
+			continue
+		}
+		for _, tool := range sdk.Info().Tools {
+			for _, dir := range dirs[:index] {
+				path, err := exec.LookPath(filepath.Join(dir, tool.Name))
+				if err != nil {
+					continue
+				}
+				findings = append(findings, &finding{problem, fmt.Sprintf("the %s command is shadowed by %s, which comes before [%s] in env.PATH", tool.Name, path, installer.BinPath),
+					fmt.Sprintf("move [%s] ahead of [%s] in env.PATH, or uninstall %s", installer.BinPath, dir, path)})
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// checkRcFile checks whether the binary paths are added to the rc file of the current terminal by `xvm activate --add_binpath`
+func checkRcFile(installer *sdks.UserIsolatedInstaller, cfg *config) []*finding {
+	rc, missing, err := binpath.MissingUserPaths(userBinPaths(installer, cfg)...)
+	if err != nil {
+		return []*finding{{problem, fmt.Sprintf("Failed to read %s: %s", rc, err), fmt.Sprintf("make sure %s is readable", rc)}}
+	}
+	if len(missing) > 0 {
+		return []*finding{{warning, fmt.Sprintf("%s does not add [%s] to env.PATH", rc, strings.Join(missing, ", ")),
+			"run `xvm activate --add_binpath` to add them"}}
+	}
+	return []*finding{{ok, fmt.Sprintf("%s adds all binary paths to env.PATH", rc), ""}}
+}
+
+// checkMirrors checks whether the mirrors of all sdks are reachable in parallel
+func checkMirrors(installer *sdks.UserIsolatedInstaller) []*finding {
+	if mirrors.Offline() {
+		return []*finding{{warning, "the mirrors are not checked in offline mode", "unset XVM_OFFLINE to check them"}}
+	}
+	findings := make([]*finding, len(installer.Sdks))
+	var wg sync.WaitGroup
+	for i, sdk := range installer.Sdks {
+		wg.Add(1)
+		go func(i int, sdk sdks.Sdk) {
+			defer wg.Done()
+			name := sdk.Info().Name
+			qualifier, _ := sdks.Qualify(sdk, "")
+			mirror := sdks.MirrorOf(sdk, qualifier)
+			if err := mirrors.Reachable(mirror, mirrorTimeout); err != nil {
+				findings[i] = &finding{problem, fmt.Sprintf("the mirror of %s is unreachable: %s", name, err),
+					fmt.Sprintf("check the network and proxy, or use a reachable mirror with the environment variable XVM_%s_MIRROR", strings.ToUpper(name))}
+				return
+			}
+			findings[i] = &finding{ok, fmt.Sprintf("the mirror of %s is reachable: %s", name, mirror.BaseURL()), ""}
+		}(i, sdk)
+	}
+	wg.Wait()
+	return findings
+}
+
+// checkInstallations checks whether any version is installed partially
+func checkInstallations(installer *sdks.UserIsolatedInstaller) []*finding {
+	var findings []*finding
+	for _, sdk := range installer.Sdks {
+		name := sdk.Info().Name
+		roots, err := installer.Incomplete(sdk)
+		if err != nil {
+			findings = append(findings, &finding{problem, fmt.Sprintf("Failed to read the installed versions of %s: %s", name, err),
+				fmt.Sprintf("make sure %s is readable", filepath.Join(installer.SdkStashPath, name))})
+			continue
+		}
+		for _, root := range roots {
+			version := filepath.Base(root)
+			findings = append(findings, &finding{problem, fmt.Sprintf("%s@v%s is not installed completely at %s", name, version, root),
+				fmt.Sprintf("run `xvm uninstall %s@%s` and install it again", name, version)})
+		}
+	}
+	if len(findings) == 0 {
+		findings = append(findings, &finding{ok, "all versions are installed completely", ""})
+	}
+	return findings
+}
+
+// realPath resolves the symbolic links of the path, which is returned as is if it cannot be resolved
+func realPath(path string) string {
+	if rp, err := filepath.EvalSymlinks(path); err == nil {
+		return rp
+	}
+	return path
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/modern-devops/xvm/sdks"
+	"github.com/modern-devops/xvm/tools"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// envPaths keeps the paths added to env.PATH by the last evaluation of `xvm env`
+	envPaths = "XVM_ENV_PATHS"
+	// envKeys keeps the names of the environment variables set by the last evaluation of `xvm env`
+	envKeys = "XVM_ENV_KEYS"
+)
+
+var envOpts = &struct {
+	Shell string
+}{}
+
+var subCommandEnv = &cobra.Command{
+	Use:   "env [--shell bash|zsh|sh|fish|powershell]",
+	Short: "Print the commands setting env.PATH and the environment variables of the versions used by the current directory",
+	Example: "Use the versions of the current directory without the linked commands: `eval \"$(xvm env)\"`\n" +
+		"Evaluate it whenever the directory changes: `eval \"$(xvm hook bash)\"`",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logToStderr()
+		shell, err := detectShell(envOpts.Shell)
+		if err != nil {
+			return err
+		}
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+		// resolve from scratch as if the last evaluation never happened
+		lastKeys := strings.FieldsFunc(os.Getenv(envKeys), func(r rune) bool { return r == ',' })
+		lastKeys = slices.DeleteFunc(lastKeys, func(key string) bool {
+			return !tools.ValidEnvName(key)
+		})
+		for _, key := range lastKeys {
+			_ = os.Unsetenv(key)
+		}
+		paths, keys, values := sdkEnvs(installer)
+		for _, key := range lastKeys {
+			if !slices.Contains(keys, key) {
+				fmt.Println(unsetCommand(shell, key))
+			}
+		}
+		for _, key := range keys {
+			fmt.Println(exportCommand(shell, key, values[key]))
+		}
+		lastPaths := filepath.SplitList(os.Getenv(envPaths))
+		fmt.Println(pathCommand(shell, append(slices.Clone(paths), slices.DeleteFunc(filepath.SplitList(os.Getenv("PATH")), func(path string) bool {
+			return slices.Contains(lastPaths, path)
+		})...)))
+		if len(paths) == 0 {
+			fmt.Println(unsetCommand(shell, envPaths))
+		} else {
+			fmt.Println(exportCommand(shell, envPaths, strings.Join(paths, string(os.PathListSeparator))))
+		}
+		if len(keys) == 0 {
+			fmt.Println(unsetCommand(shell, envKeys))
+		} else {
+			fmt.Println(exportCommand(shell, envKeys, strings.Join(keys, ",")))
+		}
+		return nil
+	},
+}
+
+// sdkEnvs returns the directories of the tools and the environment variables of the installed versions
+// used by the current directory, the sdks without a defined version are left to the linked commands
+func sdkEnvs(installer *sdks.UserIsolatedInstaller) ([]string, []string, map[string]string) {
+	var paths, keys []string
+	values := map[string]string{}
+	for _, sdk := range installer.Sdks {
+		name := sdk.Info().Name
+		spec, err := installer.GetVersion(sdk)
+		if err != nil {
+			log.Warn().Msgf("[%s] Failed to get the version defined by the current directory: %s", name, err)
+			continue
+		}
+		if spec == "" {
+			continue
+		}
+		root, err := installer.InstalledRoot(sdk, spec)
+		if err != nil {
+			log.Warn().Msgf("[%s] %s, the linked commands are used instead", name, err)
+			continue
+		}
+		st := &sdks.SdkTool{Sdk: sdk, Root: root, Defined: true, Manifest: installer.Manifest}
+		envs, err := st.Prepare()
+		if err != nil {
+			log.Warn().Msgf("[%s] Failed to prepare %s: %s, the linked commands are used instead", name, root, err)
+			continue
+		}
+		for _, tool := range sdk.Info().Tools {
+			if dir := filepath.Dir(filepath.Join(root, tool.Path)); !slices.Contains(paths, dir) {
+				paths = append(paths, dir)
+			}
+		}
+		for _, env := range envs {
+			key, value, _ := strings.Cut(env, "=")
+			if !tools.ValidEnvName(key) {
+				log.Warn().Msgf("[%s] Skip the invalid env name: %s", name, key)
+				continue
+			}
+			if _, ok := values[key]; !ok {
+				keys = append(keys, key)
+			}
+			values[key] = value
+		}
+	}
+	return paths, keys, values
+}
+
+// hooks are the scripts evaluating `xvm env` once loaded and whenever the directory changes
+var hooks = map[string]string{
+	"bash": `_xvm_hook() {
+  if [ "$PWD" != "$_XVM_PWD" ]; then
+    _XVM_PWD="$PWD"
+    eval "$(xvm env --shell bash)"
+  fi
+}
+if [[ ";${PROMPT_COMMAND:-};" != *";_xvm_hook;"* ]]; then
+  PROMPT_COMMAND="_xvm_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+_xvm_hook
+`,
+	"zsh": `_xvm_hook() {
+  eval "$(xvm env --shell zsh)"
+}
+typeset -ag chpwd_functions
+if (( ! ${chpwd_functions[(I)_xvm_hook]} )); then
+  chpwd_functions=(_xvm_hook $chpwd_functions)
+fi
+_xvm_hook
+`,
+	"fish": `function _xvm_hook --on-variable PWD
+    xvm env --shell fish | source
+end
+_xvm_hook
+`,
+}
+
+var subCommandHook = &cobra.Command{
+	Use:   "hook <bash|zsh|fish>",
+	Short: "Print the shell hook setting the environment of the versions used by the directory whenever it changes",
+	Example: "Add `eval \"$(xvm hook bash)\"` to ~/.bashrc, `eval \"$(xvm hook zsh)\"` to ~/.zshrc, " +
+		"or `xvm hook fish | source` to ~/.config/fish/config.fish",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hook, ok := hooks[args[0]]
+		if !ok {
+			return fmt.Errorf("unsupported shell: %s, allows bash,zsh,fish", args[0])
+		}
+		fmt.Print(hook)
+		return nil
+	},
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modern-devops/xvm/sdks"
+	"github.com/modern-devops/xvm/tools"
+
+	"github.com/spf13/cobra"
+)
+
+var subCommandWhich = &cobra.Command{
+	Use:           "which <tool>",
+	Short:         "Print the path of the tool used by the current directory",
+	Example:       "Print the path of the go command: `xvm which go`",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+		path, err := installer.Which(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	},
+}
+
+var subCommandExplain = &cobra.Command{
+	Use:           "explain <sdk>",
+	Short:         "Explain why the version of the sdk is used by the current directory",
+	Example:       "Show the sources checked for the go version: `xvm explain go`",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+		sdk, err := installer.GetSdk(args[0])
+		if err != nil {
+			return err
+		}
+		v, err := installer.DetectVersion(sdk)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "#\tSOURCE\tVERSION")
+		for i, c := range v.Candidates {
+			fmt.Fprintf(w, "%d\t%s\t%s\n", i+1, c.Source, candidateStatus(v, c))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		return explainResolution(installer, sdk, v)
+	},
+}
+
+func candidateStatus(v *tools.Version, c *tools.Candidate) string {
+	switch {
+	case !c.Found && strings.HasPrefix(c.Source, "env."):
+		return "not set"
+	case !c.Found:
+		return "not found"
+	case c.Version == "":
+		return "no version"
+	case c.Source == v.Source:
+		return c.Version + " <- used"
+	}
+	return c.Version
+}
+
+// explainResolution prints the version the spec resolves to and whether it is installed
+func explainResolution(installer *sdks.UserIsolatedInstaller, sdk sdks.Sdk, v *tools.Version) error {
+	name := sdk.Info().Name
+	spec := strings.TrimPrefix(v.Version, "v")
+	if spec == "" {
+		fmt.Printf("No version of %s is defined, the latest one is used\n", name)
+	}
+	version, _, err := installer.ResolveVersion(sdk, spec)
+	if err != nil {
+		return fmt.Errorf("Failed to resolve %s: %w", &sdkSpec{sdk: sdk, version: spec}, err)
+	}
+	if spec != "" && spec != version {
+		fmt.Printf("%s resolves to %s\n", spec, version)
+	}
+	path, err := installer.Which(sdkToolName(sdk))
+	if err != nil {
+		fmt.Printf("%s@v%s is not installed yet, it is installed on the first run\n", name, version)
+		return nil
+	}
+	fmt.Printf("%s@v%s is installed, runs %s\n", name, version, path)
+	return nil
+}
+
+// sdkToolName returns the name of the first tool of the sdk
+func sdkToolName(sdk sdks.Sdk) string {
+	if tools := sdk.Info().Tools; len(tools) > 0 {
+		return tools[0].Name
+	}
+	return sdk.Info().Name
+}
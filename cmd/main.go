@@ -2,26 +2,35 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"slices"
 	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
 
 	"github.com/modern-devops/xvm/mirrors"
 	"github.com/modern-devops/xvm/sdks"
 	"github.com/modern-devops/xvm/sdks/golang"
 	"github.com/modern-devops/xvm/sdks/java"
 	"github.com/modern-devops/xvm/sdks/node"
+	"github.com/modern-devops/xvm/sdks/plugin"
+	"github.com/modern-devops/xvm/sdks/python"
+	"github.com/modern-devops/xvm/tools"
 	"github.com/modern-devops/xvm/tools/binpath"
+	"github.com/modern-devops/xvm/tools/constraint"
+	"github.com/modern-devops/xvm/tools/manifest"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
-	"golang.org/x/mod/semver"
 	"gopkg.in/ini.v1"
 )
 
@@ -30,8 +39,8 @@ const app = "xvm"
 var version = "v0.0.0"
 
 func main() {
-	setFlags()
 	setLogger()
+	handleError(setFlags())
 	handleError(commandRoot.Execute())
 }
 
@@ -106,6 +115,8 @@ var subCommandExec = &cobra.Command{
 		if len(args) == 0 {
 			return cmd.Help()
 		}
+		// keep the output of the tool clean, such as the one of `go env GOROOT`
+		logToStderr()
 		installer, err := newInstaller()
 		if err != nil {
 			return err
@@ -120,9 +131,215 @@ var subCommandExec = &cobra.Command{
 	},
 }
 
+var subCommandInstall = &cobra.Command{
+	Use:   "install [sdk@version...]",
+	Short: "Install the specified sdks in parallel, or the ones resolved for the current directory",
+	Example: "Install go 1.21.0 and node 20.5.1: `xvm install go@1.21.0 node@20.5.1`\n" +
+		"Install the versions defined by the current directory: `xvm install`",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+		specs, err := parseSdkSpecs(installer, args)
+		if err != nil {
+			return err
+		}
+		if len(specs) == 0 {
+			return errors.New("no sdk versions are defined in the current directory, specify them like `xvm install go@1.21.0`")
+		}
+		return installSdkSpecs(installer, specs)
+	},
+}
+
+type sdkSpec struct {
+	sdk     sdks.Sdk
+	version string
+}
+
+func (s *sdkSpec) String() string {
+	if s.version == "" {
+		return s.sdk.Info().Name + "@latest"
+	}
+	return s.sdk.Info().Name + "@" + s.version
+}
+
+// parseSdkSpecs parses specs like go@1.21.0, the version defined by the current directory
+// is used if omitted, all sdks with defined versions are returned if no spec is given
+func parseSdkSpecs(installer *sdks.UserIsolatedInstaller, args []string) ([]*sdkSpec, error) {
+	if len(args) == 0 {
+		var specs []*sdkSpec
+		for _, sdk := range installer.Sdks {
+			version, err := installer.GetVersion(sdk)
+			if err != nil {
+				return nil, err
+			}
+			if version == "" {
+				log.Debug().Msgf("[%s] No defined version are found in the current directory", sdk.Info().Name)
+				continue
+			}
+			specs = append(specs, &sdkSpec{sdk: sdk, version: version})
+		}
+		return specs, nil
+	}
+	specs := make([]*sdkSpec, 0, len(args))
+	for _, arg := range args {
+		name, version, _ := strings.Cut(arg, "@")
+		sdk, err := installer.GetSdk(name)
+		if err != nil {
+			return nil, err
+		}
+		if version == "" {
+			if version, err = installer.GetVersion(sdk); err != nil {
+				return nil, err
+			}
+		}
+		specs = append(specs, &sdkSpec{sdk: sdk, version: strings.TrimPrefix(version, "v")})
+	}
+	return specs, nil
+}
+
+func installSdkSpecs(installer *sdks.UserIsolatedInstaller, specs []*sdkSpec) error {
+	installer.Progress = tools.NewProgress("downloading")
+	errs := make([]error, len(specs))
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		wg.Add(1)
+		go func(i int, spec *sdkSpec) {
+			defer wg.Done()
+			root, err := installer.InstallVersion(spec.sdk, spec.version)
+			if err != nil {
+				errs[i] = fmt.Errorf("Failed to install %s: %w", spec, err)
+				return
+			}
+			log.Info().Msgf("%s is installed at %s", spec, root)
+		}(i, spec)
+	}
+	wg.Wait()
+	_ = installer.Progress.Finish()
+	return errors.Join(errs...)
+}
+
+var listOpts = &struct {
+	JSON bool
+}{}
+
+var subCommandList = &cobra.Command{
+	Use:           "list [--json] [sdks...]",
+	Short:         "List the installed versions of the specified or all sdks",
+	Example:       "List the installed versions of go in json: `xvm list go --json`",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+		items, err := listInstallations(installer, args)
+		if err != nil {
+			return err
+		}
+		if listOpts.JSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(items)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SDK\tVERSION\tCURRENT\tINSTALLED\tLAST USED\tSIZE\tURL\tPROJECTS")
+		for _, item := range items {
+			current := ""
+			if item.Current {
+				current = "*"
+			}
+			lastUsed := "-"
+			if !item.LastUsed.IsZero() {
+				lastUsed = item.LastUsed.Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", item.Sdk, item.Version, current,
+				item.InstalledAt.Format(time.DateTime), lastUsed, humanizeBytes(item.Size), item.URL, strings.Join(item.Projects, ","))
+		}
+		return w.Flush()
+	},
+}
+
+type installationItem struct {
+	Sdk         string    `json:"sdk"`
+	Version     string    `json:"version"`
+	Current     bool      `json:"current"`
+	InstalledAt time.Time `json:"installedAt"`
+	LastUsed    time.Time `json:"lastUsed"`
+	Size        int64     `json:"size"`
+	URL         string    `json:"url"`
+	Root        string    `json:"root"`
+	Projects    []string  `json:"projects"`
+}
+
+func listInstallations(installer *sdks.UserIsolatedInstaller, names []string) ([]*installationItem, error) {
+	items := []*installationItem{}
+	for _, sdk := range installer.Sdks {
+		if len(names) > 0 && !slices.Contains(names, sdk.Info().Name) {
+			continue
+		}
+		installations, err := installer.Installations(sdk)
+		if err != nil {
+			return nil, err
+		}
+		spec, err := installer.GetVersion(sdk)
+		if err != nil {
+			log.Warn().Msgf("[%s] Failed to get the version defined by the current directory: %s", sdk.Info().Name, err)
+		}
+		current := ""
+		if spec != "" {
+			current = installer.InstalledVersion(sdk, spec)
+		}
+		slices.SortFunc(installations, func(a, b *sdks.Installation) int {
+			return sdks.CompareVersions(sdk, a.Version, b.Version)
+		})
+		for _, in := range installations {
+			size, err := in.DiskUsage()
+			if err != nil {
+				return nil, err
+			}
+			usage, err := in.Usage()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, &installationItem{
+				Sdk:         in.Name,
+				Version:     in.Version,
+				Current:     in.Version == current,
+				InstalledAt: in.InstalledAt,
+				LastUsed:    usage.LastUsed,
+				Size:        size,
+				URL:         in.URL,
+				Root:        in.Root,
+				Projects:    usage.Projects,
+			})
+		}
+	}
+	return items, nil
+}
+
+func humanizeBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
 var subCommandShow = &cobra.Command{
-	Use:           "show",
-	Short:         "Show xvm details",
+	Use:           "show [sdk]",
+	Short:         "Show xvm details, or the details of the sdk",
+	Example:       "Show the details of go: `xvm show go`",
+	Args:          cobra.MaximumNArgs(1),
 	SilenceErrors: true,
 	SilenceUsage:  true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -130,6 +347,13 @@ var subCommandShow = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if len(args) == 1 {
+			sdk, err := installer.GetSdk(args[0])
+			if err != nil {
+				return err
+			}
+			return showSDK(installer, sdk)
+		}
 		cfg := &config{path: installer.ConfigPath}
 		if err := cfg.load(); err != nil {
 			return err
@@ -152,40 +376,23 @@ var subCommandShow = &cobra.Command{
 	},
 }
 
-func addSubCommandShowSDKS() error {
-	installer, err := newInstaller()
-	if err != nil {
-		return err
-	}
-	for _, sdk := range installer.Sdks {
-		subCommandShow.AddCommand(subCommandShowSDK(installer, sdk))
-	}
-	return nil
-}
-
-func subCommandShowSDK(installer *sdks.UserIsolatedInstaller, sdk sdks.Sdk) *cobra.Command {
-	return &cobra.Command{
-		Use:           sdk.Info().Name,
-		Short:         fmt.Sprintf("Show %s details", sdk.Info().Name),
-		SilenceErrors: true,
-		SilenceUsage:  true,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return showSDK(installer, sdk)
-		},
-	}
-}
-
 func showSDK(installer *sdks.UserIsolatedInstaller, sdk sdks.Sdk) error {
 	uv, err := installer.GetVersion(sdk)
 	if err != nil {
 		return fmt.Errorf("Failed to get the %s version defined by the current directory: %s", sdk.Info().Name, err)
 	}
 	if uv != "" {
-		log.Info().Msgf("[%s] The current directory is set to version v%s", sdk.Info().Name, uv)
+		log.Info().Msgf("[%s] The current directory is set to version %s", sdk.Info().Name, uv)
 	} else {
 		log.Warn().Msgf("[%s] No defined version are found in the current directory", sdk.Info().Name)
 	}
-	versions, err := sdk.Info().Mirror.Versions()
+	qualifier, _ := sdks.Qualify(sdk, uv)
+	if qualifier != "" {
+		if err := showQualifiedInstallations(installer, sdk); err != nil {
+			return err
+		}
+	}
+	versions, err := sdks.MirrorOf(sdk, qualifier).Versions()
 	if err != nil {
 		return fmt.Errorf("Failed to get all available versions for %s: %w", sdk.Info().Name, err)
 	}
@@ -193,13 +400,52 @@ func showSDK(installer *sdks.UserIsolatedInstaller, sdk sdks.Sdk) error {
 	log.Info().Msgf("[%s] Some newer versions of the current machine:", sdk.Info().Name)
 	for i := 0; i < 10 && i < len(versions); i++ {
 		cv := versions[len(versions)-1-i]
+		if cv.LTS != "" {
+			log.Info().Msgf("%02d. version: %s (LTS: %s), url: %s", i+1, cv.Version, cv.LTS, cv.URL)
+			continue
+		}
 		log.Info().Msgf("%02d. version: %s, url: %s", i+1, cv.Version, cv.URL)
 	}
 	return nil
 }
 
+// showQualifiedInstallations shows the installed versions grouped by the qualifier, such as the distribution of java
+func showQualifiedInstallations(installer *sdks.UserIsolatedInstaller, sdk sdks.Sdk) error {
+	installations, err := installer.Installations(sdk)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(installations, func(a, b *sdks.Installation) int {
+		return sdks.CompareVersions(sdk, a.Version, b.Version)
+	})
+	groups := map[string][]string{}
+	var qualifiers []string
+	for _, in := range installations {
+		_, version := sdks.Qualify(sdk, in.Version)
+		if in.Qualifier == "" {
+			// installed before the versions were qualified
+			version = in.Version
+		}
+		if _, ok := groups[in.Qualifier]; !ok {
+			qualifiers = append(qualifiers, in.Qualifier)
+		}
+		groups[in.Qualifier] = append(groups[in.Qualifier], version)
+	}
+	if len(qualifiers) == 0 {
+		log.Info().Msgf("[%s] No installed versions", sdk.Info().Name)
+	}
+	for _, qualifier := range qualifiers {
+		name := qualifier
+		if name == "" {
+			name = "unqualified"
+		}
+		log.Info().Msgf("[%s] Installed versions of %s: %s", sdk.Info().Name, name, strings.Join(groups[qualifier], ", "))
+	}
+	return nil
+}
+
 func sort(a, b *mirrors.VersionDesc) int {
-	return semver.Compare(a.Version, b.Version)
+	return constraint.Compare(a.Version, b.Version)
 }
 
 type config struct {
@@ -223,20 +469,62 @@ func (c *config) save() error {
 	return cfg.SaveToIndent(c.path, "\t")
 }
 
+// newInstaller loads the installer along with the manifest of the working directory and the plugin sdks,
+// the env and mirrors of the project manifests and the project plugins are skipped unless trusted by the user
 func newInstaller() (*sdks.UserIsolatedInstaller, error) {
-	home, err := os.UserHomeDir()
+	installer, err := userInstaller()
 	if err != nil {
 		return nil, err
 	}
-	installer := sdks.NewUserIsolatedInstaller(home, nil)
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	m, err := manifest.Load(wd, trustedBy(installer, "its env and mirrors are ignored"))
+	if err != nil {
+		return nil, err
+	}
+	installer.Manifest = m
+	mirrors.UseOverrides(m.Mirrors)
+	home := filepath.Dir(installer.RootPath)
 	installer.Sdks = []sdks.Sdk{
 		golang.Gvm(home),
 		node.Nvm(installer.DataPath),
 		java.Jvm(home),
+		python.Pvm(installer.DataPath),
 	}
+	trusted := trustedBy(installer, "the plugin is ignored")
+	files := slices.DeleteFunc(plugin.Files(pluginPaths(installer)...), func(file string) bool {
+		return !trusted(file)
+	})
+	installer.Sdks = append(installer.Sdks, plugin.LoadFiles(home, supportedSdkNames(installer.Sdks), files...)...)
 	return installer, nil
 }
 
+// userInstaller returns the installer of the user without any sdks
+func userInstaller() (*sdks.UserIsolatedInstaller, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	installer := sdks.NewUserIsolatedInstaller(home, nil)
+	mirrors.UseCache(mirrors.NewCache(installer.CachePath))
+	return installer, nil
+}
+
+// pluginPaths returns the directories of the plugin sdks, the user's one and the one of the current project
+func pluginPaths(installer *sdks.UserIsolatedInstaller) []string {
+	paths := []string{installer.PluginPath}
+	wd, err := os.Getwd()
+	if err != nil {
+		return paths
+	}
+	if pp := filepath.Join(tools.GitRootPath(wd), ".xvm", "plugins"); pp != installer.PluginPath {
+		paths = append(paths, pp)
+	}
+	return paths
+}
+
 func filterSdkNames(supportedSdks []string, sdks []string) []string {
 	var items []string
 	for _, item := range sdks {
@@ -260,17 +548,22 @@ func addBinPath(installer *sdks.UserIsolatedInstaller, cfg *config) error {
 }
 
 func getBinPath(installer *sdks.UserIsolatedInstaller, cfg *config) ([]string, error) {
+	binPaths := userBinPaths(installer, cfg)
+	log.Info().Strs(app, binPaths).Msg("Found binary paths")
+	return binPaths, nil
+}
+
+// userBinPaths returns the binary path of xvm and the ones of the activated sdks, which are added to env.PATH
+func userBinPaths(installer *sdks.UserIsolatedInstaller, cfg *config) []string {
 	binPaths := []string{installer.BinPath}
-	log.Info().Strs(app, []string{installer.BinPath}).Msg("Found binary paths")
 	for _, sn := range cfg.Sdks {
 		sdk, err := installer.GetSdk(sn)
 		if err != nil {
 			continue
 		}
-		log.Info().Strs(sdk.Info().Name, sdk.Info().BinPaths).Msg("Found binary paths")
 		binPaths = append(binPaths, sdk.Info().BinPaths...)
 	}
-	return binPaths, nil
+	return binPaths
 }
 
 func supportedSdkNames(sdks []sdks.Sdk) []string {
@@ -295,10 +588,21 @@ func handleError(err error) {
 }
 
 func setFlags() error {
-	if err := addSubCommandShowSDKS(); err != nil {
-		return err
-	}
-	commandRoot.AddCommand(subCommandActivate, subCommandExec, subCommandShow)
+	commandRoot.AddCommand(subCommandActivate, subCommandExec, subCommandInstall, subCommandList, subCommandShow)
+	commandRoot.AddCommand(subCommandUninstall, subCommandPrune, subCommandPin, subCommandUse)
+	commandRoot.AddCommand(subCommandWhich, subCommandExplain, subCommandDoctor)
+	commandRoot.AddCommand(subCommandEnv, subCommandHook, subCommandShell)
+	commandRoot.AddCommand(subCommandAllow, subCommandDeny)
+	subCommandList.Flags().BoolVar(&listOpts.JSON, "json", false, "Print the installed versions in json")
+	subCommandPrune.Flags().IntVar(&pruneOpts.KeepLast, "keep-last", 0, "Keep the N most recently used versions of each sdk")
+	subCommandPrune.Flags().StringVar(&pruneOpts.OlderThan, "older-than", "", "Only remove versions not used for the duration, such as 30d or 12h")
+	subCommandPrune.Flags().BoolVar(&pruneOpts.KeepPinned, "keep-pinned", false, "Keep versions still referenced by the version files of the projects using them")
+	subCommandPrune.Flags().BoolVar(&pruneOpts.DryRun, "dry-run", false, "Only print the versions to remove")
+	subCommandPin.Flags().BoolVar(&pinOpts.Here, "here", false, "Pin the version in the current directory instead of the git root")
+	subCommandPin.Flags().BoolVar(&pinOpts.Global, "global", false, "Pin the version in the user's home as the fallback of all projects")
+	subCommandUse.Flags().StringVar(&useOpts.Shell, "shell", "", "The shell to print the commands for, detected from $SHELL by default")
+	subCommandEnv.Flags().StringVar(&envOpts.Shell, "shell", "", "The shell to print the commands for, detected from $SHELL by default")
+	subCommandShell.Flags().StringVar(&shellOpts.Shell, "shell", "", "The shell to start, $SHELL by default")
 	subCommandActivate.Flags().BoolVar(&activateOpts.AddBinPath, "add_binpath", false, "Add xvm's binary path to the user's sdks.PATH, On a unix like system, all identified terminal rc files, such as ~/.bashrc and ~.zshrc, will be modified.")
 	subCommandActivate.Flags().BoolVarP(&activateOpts.All, "all", "a", false, "Activate all supported sdks, execute `xvm list` to see detail")
 	return nil
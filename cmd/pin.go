@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modern-devops/xvm/sdks"
+	"github.com/modern-devops/xvm/tools"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var pinOpts = &struct {
+	Here   bool
+	Global bool
+}{}
+
+var subCommandPin = &cobra.Command{
+	Use:   "pin [--here|--global] <sdk> <version>",
+	Short: "Pin the version of the sdk in its version file, in the git root of the current directory by default",
+	Example: "Pin go to the latest 1.21 patch in the git root: `xvm pin go 1.21`\n" +
+		"Pin node 20 for the current user: `xvm pin --global node 20`",
+	Args:          cobra.ExactArgs(2),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if pinOpts.Here && pinOpts.Global {
+			return errors.New("only one of --here and --global is allowed")
+		}
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+		sdk, err := installer.GetSdk(args[0])
+		if err != nil {
+			return err
+		}
+		name := sdk.Info().Name
+		if sdk.Info().VersionFile == "" {
+			return fmt.Errorf("%s declares no version file to pin the version", name)
+		}
+		dir, err := pinDir()
+		if err != nil {
+			return err
+		}
+		version, _, err := installer.ResolveMirrorVersion(sdk, strings.TrimPrefix(args[1], "v"))
+		if err != nil {
+			return err
+		}
+		if _, err := installer.InstallVersion(sdk, version); err != nil {
+			return err
+		}
+		vf := filepath.Join(dir, sdk.Info().VersionFile)
+		previous := ""
+		if data, err := os.ReadFile(vf); err == nil {
+			previous, _ = tools.ParseVersionLine(data)
+		}
+		if err := os.WriteFile(vf, []byte(version+"\n"), 0644); err != nil {
+			return fmt.Errorf("Failed to write: [%s], Please check: %w", vf, err)
+		}
+		switch previous {
+		case "":
+			log.Info().Msgf("[%s] Pinned v%s in %s", name, version, vf)
+		case version:
+			log.Info().Msgf("[%s] v%s is already pinned in %s", name, version, vf)
+		default:
+			log.Info().Msgf("[%s] Pinned v%s in %s, which was %s", name, version, vf, previous)
+		}
+		warnOverridden(installer, sdk, version)
+		return nil
+	},
+}
+
+// pinDir returns the directory to write the version file into
+func pinDir() (string, error) {
+	if pinOpts.Global {
+		return os.UserHomeDir()
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if pinOpts.Here {
+		return wd, nil
+	}
+	return tools.GitRootPath(wd), nil
+}
+
+// warnOverridden warns if the current directory still uses another version than the pinned one,
+// which is defined by an environment variable or a version file of higher precedence
+func warnOverridden(installer *sdks.UserIsolatedInstaller, sdk sdks.Sdk, version string) {
+	spec, err := installer.GetVersion(sdk)
+	if err != nil || spec == version || installer.InstalledVersion(sdk, spec) == version {
+		return
+	}
+	log.Warn().Msgf("[%s] The current directory still uses %s, which is defined with a higher precedence", sdk.Info().Name, spec)
+}
+
+var useOpts = &struct {
+	Shell string
+}{}
+
+var subCommandUse = &cobra.Command{
+	Use:   "use [--shell bash|zsh|sh|fish|powershell] <sdk@version...>",
+	Short: "Print the commands using the versions of sdks in the current shell session only",
+	Example: "Use go 1.20 in the current shell: `eval \"$(xvm use go@1.20)\"`\n" +
+		"Use node 18 in powershell: `xvm use node@18 | Invoke-Expression`",
+	Args:          cobra.MinimumNArgs(1),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logToStderr()
+		shell, err := detectShell(useOpts.Shell)
+		if err != nil {
+			return err
+		}
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+		for _, arg := range args {
+			name, spec, _ := strings.Cut(arg, "@")
+			if spec == "" {
+				return fmt.Errorf("no version is specified: %s, uses sdk@version instead", arg)
+			}
+			sdk, err := installer.GetSdk(name)
+			if err != nil {
+				return err
+			}
+			version, _, err := installer.ResolveVersion(sdk, strings.TrimPrefix(spec, "v"))
+			if err != nil {
+				return err
+			}
+			if _, err := installer.InstallVersion(sdk, version); err != nil {
+				return err
+			}
+			fmt.Println(exportCommand(shell, sdks.VersionEnv(sdk), version))
+			log.Info().Msgf("[%s] Uses v%s in the current shell session", sdk.Info().Name, version)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modern-devops/xvm/sdks"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var subCommandUninstall = &cobra.Command{
+	Use:           "uninstall <sdk@version...>",
+	Short:         "Uninstall the specified versions of sdks",
+	Example:       "Uninstall go 1.20.1: `xvm uninstall go@1.20.1`",
+	Args:          cobra.MinimumNArgs(1),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+		for _, arg := range args {
+			name, version, _ := strings.Cut(arg, "@")
+			if version == "" {
+				return fmt.Errorf("no version is specified: %s, uses sdk@version instead", arg)
+			}
+			sdk, err := installer.GetSdk(name)
+			if err != nil {
+				return err
+			}
+			version = strings.TrimPrefix(version, "v")
+			if err := installer.Uninstall(sdk, version); err != nil {
+				return err
+			}
+			log.Info().Msgf("%s@v%s is uninstalled", name, version)
+		}
+		return nil
+	},
+}
+
+var pruneOpts = &struct {
+	KeepLast   int
+	OlderThan  string
+	KeepPinned bool
+	DryRun     bool
+}{}
+
+var subCommandPrune = &cobra.Command{
+	Use:   "prune [--keep-last N] [--older-than 30d] [--keep-pinned] [--dry-run] [sdks...]",
+	Short: "Remove the installed versions of the specified or all sdks that are no longer used",
+	Example: "Remove versions not used for 30 days but the 2 most recently used ones and the pinned ones: " +
+		"`xvm prune --older-than 30d --keep-last 2 --keep-pinned`",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if pruneOpts.KeepLast <= 0 && pruneOpts.OlderThan == "" {
+			return errors.New("at least one of --keep-last and --older-than is required")
+		}
+		var age time.Duration
+		if pruneOpts.OlderThan != "" {
+			var err error
+			if age, err = parseAge(pruneOpts.OlderThan); err != nil {
+				return err
+			}
+		}
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+		for _, sdk := range installer.Sdks {
+			if len(args) > 0 && !slices.Contains(args, sdk.Info().Name) {
+				continue
+			}
+			if err := prune(installer, sdk, age); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+type pruneCandidate struct {
+	installation *sdks.Installation
+	lastUsed     time.Time
+	projects     []string
+}
+
+func prune(installer *sdks.UserIsolatedInstaller, sdk sdks.Sdk, age time.Duration) error {
+	installations, err := installer.Installations(sdk)
+	if err != nil {
+		return err
+	}
+	candidates := make([]*pruneCandidate, 0, len(installations))
+	for _, in := range installations {
+		usage, err := in.Usage()
+		if err != nil {
+			return err
+		}
+		lastUsed := usage.LastUsed
+		// never used since installed
+		if lastUsed.IsZero() {
+			lastUsed = in.InstalledAt
+		}
+		candidates = append(candidates, &pruneCandidate{installation: in, lastUsed: lastUsed, projects: usage.Projects})
+	}
+	// the most recently used first
+	slices.SortFunc(candidates, func(a, b *pruneCandidate) int {
+		return b.lastUsed.Compare(a.lastUsed)
+	})
+	for i, c := range candidates {
+		in := c.installation
+		if pruneOpts.KeepLast > 0 && i < pruneOpts.KeepLast {
+			log.Debug().Msgf("[%s] Keeps v%s, which is one of the %d most recently used versions", in.Name, in.Version, pruneOpts.KeepLast)
+			continue
+		}
+		if age > 0 && time.Since(c.lastUsed) < age {
+			log.Debug().Msgf("[%s] Keeps v%s, which was used at %s", in.Name, in.Version, c.lastUsed.Format(time.DateTime))
+			continue
+		}
+		if pruneOpts.KeepPinned {
+			if project := pinnedBy(installer, sdk, in.Version, c.projects); project != "" {
+				log.Debug().Msgf("[%s] Keeps v%s, which is pinned by %s", in.Name, in.Version, project)
+				continue
+			}
+		}
+		if pruneOpts.DryRun {
+			log.Info().Msgf("[%s] Would remove v%s, last used at %s", in.Name, in.Version, c.lastUsed.Format(time.DateTime))
+			continue
+		}
+		if err := installer.Uninstall(sdk, in.Version); err != nil {
+			log.Warn().Msgf("[%s] Skip removing v%s: %s", in.Name, in.Version, err)
+			continue
+		}
+		log.Info().Msgf("[%s] Removed v%s, last used at %s", in.Name, in.Version, c.lastUsed.Format(time.DateTime))
+	}
+	return nil
+}
+
+// pinnedBy returns the project whose version files still reference the version, the current directory is checked as well
+func pinnedBy(installer *sdks.UserIsolatedInstaller, sdk sdks.Sdk, version string, projects []string) string {
+	if wd, err := os.Getwd(); err == nil {
+		projects = append([]string{wd}, projects...)
+	}
+	for _, project := range projects {
+		if _, err := os.Stat(project); err != nil {
+			continue
+		}
+		pv, err := installer.ProjectVersion(sdk, project)
+		if err != nil {
+			log.Debug().Msgf("[%s] Failed to get the version defined by %s: %s", sdk.Info().Name, project, err)
+			continue
+		}
+		if pv != "" && installer.InstalledVersion(sdk, pv) == version {
+			return project
+		}
+	}
+	return ""
+}
+
+// parseAge parses durations like 30d in addition to the ones supported by time.ParseDuration
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration: %s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return d, nil
+}
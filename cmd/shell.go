@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/modern-devops/xvm/sdks"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// shells are the shells whose commands can be printed for evaluating
+var shells = []string{"bash", "zsh", "sh", "fish", "powershell"}
+
+// detectShell returns the shell of the current user, the flag value wins if given
+func detectShell(flag string) (string, error) {
+	shell := flag
+	if shell == "" {
+		shell = strings.TrimSuffix(filepath.Base(os.Getenv("SHELL")), ".exe")
+	}
+	switch shell {
+	case "bash", "zsh", "sh", "fish", "powershell":
+		return shell, nil
+	case "pwsh":
+		return "powershell", nil
+	case "", ".":
+		if os.Getenv("PSModulePath") != "" {
+			return "powershell", nil
+		}
+		return "sh", nil
+	}
+	return "", fmt.Errorf("unsupported shell: %s, allows %s", shell, strings.Join(shells, ","))
+}
+
+// exportCommand returns the command of the shell setting the environment variable
+func exportCommand(shell, key, value string) string {
+	switch shell {
+	case "fish":
+		return fmt.Sprintf("set -gx %s %s;", key, quote(shell, value))
+	case "powershell":
+		return fmt.Sprintf("$env:%s = %s", key, quote(shell, value))
+	default:
+		return fmt.Sprintf("export %s=%s", key, quote(shell, value))
+	}
+}
+
+// unsetCommand returns the command of the shell removing the environment variable
+func unsetCommand(shell, key string) string {
+	switch shell {
+	case "fish":
+		return fmt.Sprintf("set -e %s;", key)
+	case "powershell":
+		return fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue", key)
+	default:
+		return fmt.Sprintf("unset %s", key)
+	}
+}
+
+// pathCommand returns the command of the shell setting env.PATH to the paths
+func pathCommand(shell string, paths []string) string {
+	if shell == "fish" {
+		// fish keeps PATH as a list
+		quoted := make([]string, 0, len(paths))
+		for _, path := range paths {
+			quoted = append(quoted, quote(shell, path))
+		}
+		return fmt.Sprintf("set -gx PATH %s;", strings.Join(quoted, " "))
+	}
+	return exportCommand(shell, "PATH", strings.Join(paths, string(os.PathListSeparator)))
+}
+
+func quote(shell, value string) string {
+	if shell == "powershell" {
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// logToStderr keeps the stdout clean for the commands evaluated by the shell
+func logToStderr() {
+	if assertEnvTrue("SILENT") {
+		return
+	}
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+var shellOpts = &struct {
+	Shell string
+}{}
+
+var subCommandShell = &cobra.Command{
+	Use:   "shell [--shell bash|zsh|sh|fish|powershell] <sdk@version...>",
+	Short: "Start a subshell using the versions of sdks, exit it to return to the previous ones",
+	Example: "Try go 1.20 and node 18 in a subshell: `xvm shell go@1.20 node@18`\n" +
+		"Start zsh instead of $SHELL: `xvm shell --shell zsh go@1.20`",
+	Args:          cobra.MinimumNArgs(1),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell, err := detectShell(shellOpts.Shell)
+		if err != nil {
+			return err
+		}
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+		specs := make([]*sdkSpec, 0, len(args))
+		for _, arg := range args {
+			name, spec, _ := strings.Cut(arg, "@")
+			if spec == "" {
+				return fmt.Errorf("no version is specified: %s, uses sdk@version instead", arg)
+			}
+			sdk, err := installer.GetSdk(name)
+			if err != nil {
+				return err
+			}
+			version, _, err := installer.ResolveVersion(sdk, strings.TrimPrefix(spec, "v"))
+			if err != nil {
+				return err
+			}
+			specs = append(specs, &sdkSpec{sdk: sdk, version: version})
+		}
+		if err := installSdkSpecs(installer, specs); err != nil {
+			return err
+		}
+		envs, err := subshellEnvs(installer, specs)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(specs))
+		for _, spec := range specs {
+			names = append(names, spec.String())
+		}
+		temp, err := os.MkdirTemp("", "xvm-shell")
+		if err != nil {
+			return fmt.Errorf("Failed to make temp dir: %w", err)
+		}
+		defer func() {
+			_ = os.RemoveAll(temp)
+		}()
+		shellArgs, shellEnvs, err := decorateShell(shell, fmt.Sprintf("(%s) ", strings.Join(names, " ")), temp)
+		if err != nil {
+			return err
+		}
+		program := shellProgram(shell)
+		log.Info().Msgf("Starting %s with %s, exit it to return to the previous versions", program, strings.Join(names, ", "))
+		sc := exec.Command(program, shellArgs...)
+		sc.Env = append(envs, shellEnvs...)
+		sc.Stdin = os.Stdin
+		sc.Stdout = os.Stdout
+		sc.Stderr = os.Stderr
+		// the interrupts of the terminal are handled by the subshell
+		signal.Notify(make(chan os.Signal, 1), os.Interrupt)
+		return sc.Run()
+	},
+}
+
+// subshellEnvs returns the environment of the subshell, in which the versions are given by XVM_<SDK>_VERSION,
+// and the tools of the versions come first in env.PATH
+func subshellEnvs(installer *sdks.UserIsolatedInstaller, specs []*sdkSpec) ([]string, error) {
+	envs := os.Environ()
+	var paths []string
+	for _, spec := range specs {
+		root, err := installer.InstalledRoot(spec.sdk, spec.version)
+		if err != nil {
+			return nil, err
+		}
+		st := &sdks.SdkTool{Sdk: spec.sdk, Root: root, Manifest: installer.Manifest}
+		se, err := st.Prepare()
+		if err != nil {
+			return nil, err
+		}
+		envs = append(envs, sdks.VersionEnv(spec.sdk)+"="+spec.version)
+		envs = append(envs, se...)
+		for _, tool := range spec.sdk.Info().Tools {
+			if dir := filepath.Dir(filepath.Join(root, tool.Path)); !slices.Contains(paths, dir) {
+				paths = append(paths, dir)
+			}
+		}
+	}
+	// the later value of a duplicated variable wins
+	return append(envs, "PATH="+strings.Join(append(paths, os.Getenv("PATH")), string(os.PathListSeparator))), nil
+}
+
+// shellProgram returns the program of the shell, which is $SHELL unless another shell is given
+func shellProgram(shell string) string {
+	if program := os.Getenv("SHELL"); program != "" && (shellOpts.Shell == "" || filepath.Base(program) == shell) {
+		return program
+	}
+	if shell == "powershell" {
+		if _, err := exec.LookPath("pwsh"); err == nil {
+			return "pwsh"
+		}
+	}
+	return shell
+}
+
+// decorateShell returns the arguments and environment variables starting the shell with the prompt prefixed,
+// the rc files loading the user's ones are written to the temp dir if needed
+func decorateShell(shell, prefix, temp string) ([]string, []string, error) {
+	switch shell {
+	case "bash":
+		rc := filepath.Join(temp, ".bashrc")
+		script := fmt.Sprintf("[ -f ~/.bashrc ] && . ~/.bashrc\nPS1=%s\"$PS1\"\n", quote(shell, prefix))
+		if err := os.WriteFile(rc, []byte(script), 0644); err != nil {
+			return nil, nil, fmt.Errorf("Failed to create file: %s, %w", rc, err)
+		}
+		return []string{"--rcfile", rc}, nil, nil
+	case "zsh":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil, err
+		}
+		zdotdir := os.Getenv("ZDOTDIR")
+		if zdotdir == "" {
+			zdotdir = home
+		}
+		// zsh reads the rc files from $ZDOTDIR, which is restored once they are loaded
+		files := map[string]string{
+			".zshenv": fmt.Sprintf("[ -f %[1]s/.zshenv ] && . %[1]s/.zshenv\n", quote(shell, zdotdir)),
+			".zshrc": fmt.Sprintf("ZDOTDIR=%[1]s\n[ -f %[1]s/.zshrc ] && . %[1]s/.zshrc\nPROMPT=%[2]s\"$PROMPT\"\n",
+				quote(shell, zdotdir), quote(shell, prefix)),
+		}
+		for name, script := range files {
+			if err := os.WriteFile(filepath.Join(temp, name), []byte(script), 0644); err != nil {
+				return nil, nil, fmt.Errorf("Failed to create file: %s, %w", filepath.Join(temp, name), err)
+			}
+		}
+		return nil, []string{"ZDOTDIR=" + temp}, nil
+	case "fish":
+		return []string{"-C", fmt.Sprintf("functions -c fish_prompt _xvm_fish_prompt; "+
+			"function fish_prompt; printf '%%s' %s; _xvm_fish_prompt; end", quote(shell, prefix))}, nil, nil
+	case "powershell":
+		return []string{"-NoExit", "-Command", fmt.Sprintf("$function:_xvm_prompt = $function:prompt; "+
+			"function global:prompt { %s + (& $function:_xvm_prompt) }", quote(shell, prefix))}, nil, nil
+	default:
+		return nil, []string{"PS1=" + prefix + "$ "}, nil
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/modern-devops/xvm/sdks"
+	"github.com/modern-devops/xvm/sdks/plugin"
+	"github.com/modern-devops/xvm/tools/manifest"
+	"github.com/modern-devops/xvm/tools/trust"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var subCommandAllow = &cobra.Command{
+	Use:   "allow",
+	Short: "Trust the current contents of the project manifests and plugins used by the current directory",
+	Example: "Let `xvm env` and `xvm hook` export the env of the .xvm.toml and load the plugins of a cloned project: " +
+		"`xvm allow`",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installer, err := userInstaller()
+		if err != nil {
+			return err
+		}
+		files, err := projectFiles(installer)
+		if err != nil {
+			return err
+		}
+		if err := (&trust.Store{Path: installer.TrustPath}).Allow(files...); err != nil {
+			return err
+		}
+		for _, file := range files {
+			log.Info().Msgf("%s is trusted", file)
+		}
+		return nil
+	},
+}
+
+var subCommandDeny = &cobra.Command{
+	Use:           "deny",
+	Short:         "Revoke the trust of the project manifests and plugins used by the current directory",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installer, err := userInstaller()
+		if err != nil {
+			return err
+		}
+		files, err := projectFiles(installer)
+		if err != nil {
+			return err
+		}
+		if err := (&trust.Store{Path: installer.TrustPath}).Deny(files...); err != nil {
+			return err
+		}
+		for _, file := range files {
+			log.Info().Msgf("%s is no longer trusted", file)
+		}
+		return nil
+	},
+}
+
+// projectFiles returns the manifests and plugins used by the current directory, except the user's own ones
+func projectFiles(installer *sdks.UserIsolatedInstaller) ([]string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	files, err := manifest.Files(wd)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, plugin.Files(pluginPaths(installer)...)...)
+	var pfs []string
+	for _, file := range files {
+		if !ownFile(installer, file) {
+			pfs = append(pfs, file)
+		}
+	}
+	return pfs, nil
+}
+
+// trustedBy returns whether a file is trusted by the user, who owns the manifest in the home and the plugins of
+// ~/.xvm/plugins, the untrusted files are warned about along with what is ignored
+func trustedBy(installer *sdks.UserIsolatedInstaller, ignored string) func(file string) bool {
+	store := &trust.Store{Path: installer.TrustPath}
+	return func(file string) bool {
+		if ownFile(installer, file) || store.Trusted(file) {
+			return true
+		}
+		log.Warn().Msgf("%s is not trusted or has changed, %s, run `xvm allow` to trust it", file, ignored)
+		return false
+	}
+}
+
+func ownFile(installer *sdks.UserIsolatedInstaller, file string) bool {
+	dir := filepath.Dir(file)
+	return dir == filepath.Dir(installer.RootPath) || dir == installer.PluginPath
+}
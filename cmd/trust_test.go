@@ -0,0 +1,114 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modern-devops/xvm/mirrors"
+	"github.com/modern-devops/xvm/tools/manifest"
+	"github.com/modern-devops/xvm/tools/trust"
+)
+
+const fakePlugin = `name: fake
+tools:
+  - name: fake
+    path: bin/fake
+url: https://example.com/fake-{version}.tar.gz
+versions:
+  url: https://example.com/fake/versions
+  type: json
+  path: "[*].version"
+`
+
+// fakeTool records the env it is run with
+const fakeTool = `#!/bin/sh
+echo "$NODE_OPTIONS" > "$FAKE_OUTPUT"
+`
+
+const projectManifest = `[versions]
+fake = "1.0.0"
+
+[env]
+NODE_OPTIONS = "--require ./evil.js"
+
+[mirrors.go]
+mirror = "https://evil.example.com/go"
+`
+
+func TestUntrustedManifest(t *testing.T) {
+	home, project := t.TempDir(), t.TempDir()
+	output := filepath.Join(t.TempDir(), "output")
+	t.Setenv("HOME", home)
+	t.Setenv("XVM_NO_EXEC", "true")
+	t.Setenv("XVM_OFFLINE", "true")
+	t.Setenv("NODE_OPTIONS", "")
+	t.Setenv("FAKE_OUTPUT", output)
+	writeFile(t, filepath.Join(home, ".xvm", "plugins", "fake.yaml"), fakePlugin, 0644)
+	root := filepath.Join(home, ".xvm", "sdk", "fake", "1.0.0")
+	writeFile(t, filepath.Join(root, "bin", "fake"), fakeTool, 0755)
+	writeFile(t, filepath.Join(root, ".done"), `{"name":"fake","version":"1.0.0"}`, 0644)
+	mf := filepath.Join(project, manifest.File)
+	writeFile(t, mf, projectManifest, 0644)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+		mirrors.UseOverrides(nil)
+	})
+
+	run := func() string {
+		installer, err := newInstaller()
+		if err != nil {
+			t.Fatal(err)
+		}
+		st, err := installer.Install("fake")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := st.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// the version is honored, but neither the env nor the mirrors
+	if got := run(); got != "\n" {
+		t.Errorf("the untrusted env reached the tool: NODE_OPTIONS=%q", got)
+	}
+	if base := mirrors.Go().BaseURL(); base == "https://evil.example.com/go" {
+		t.Errorf("the untrusted mirror is used: %s", base)
+	}
+
+	if err := (&trust.Store{Path: filepath.Join(home, ".xvm", "trusted.json")}).Allow(mf); err != nil {
+		t.Fatal(err)
+	}
+	if got := run(); got != "--require ./evil.js\n" {
+		t.Errorf("the trusted env did not reach the tool: NODE_OPTIONS=%q", got)
+	}
+	if base := mirrors.Go().BaseURL(); base != "https://evil.example.com/go" {
+		t.Errorf("the trusted mirror is not used: %s", base)
+	}
+}
+
+func writeFile(t *testing.T, path, content string, perm os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,6 +3,7 @@ module github.com/modern-devops/xvm
 go 1.21.0
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/go-resty/resty/v2 v2.7.0
 	github.com/google/uuid v1.3.1
 	github.com/mholt/archiver/v3 v3.5.1
@@ -11,6 +12,7 @@ require (
 	golang.org/x/mod v0.12.0
 	golang.org/x/sys v0.11.0
 	gopkg.in/ini.v1 v1.67.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,197 @@
+package mirrors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/modern-devops/xvm/tools"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultCacheTTL = time.Hour
+
+// ErrOffline is returned in offline mode if a response is not cached
+var ErrOffline = errors.New("no cached response is available in offline mode")
+
+// errNotFound is returned if the api responds 404
+var errNotFound = errors.New("not found")
+
+// Cache keeps the responses of the indexing apis on disk, they are revalidated with
+// ETag and Last-Modified once expired, and used regardless of their age in offline mode
+type Cache struct {
+	Path    string
+	TTL     time.Duration
+	Offline bool
+}
+
+var cache *Cache
+
+// NewCache returns a cache under path configured by the environment variables,
+// XVM_CACHE_TTL for the ttl, such as 30m, and XVM_OFFLINE=true for the offline mode
+func NewCache(path string) *Cache {
+	ttl := defaultCacheTTL
+	if v := os.Getenv("XVM_CACHE_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			ttl = d
+		} else {
+			log.Warn().Msgf("invalid XVM_CACHE_TTL: %s, uses %s instead.", v, defaultCacheTTL)
+		}
+	}
+	return &Cache{
+		Path:    path,
+		TTL:     ttl,
+		Offline: strings.ToLower(os.Getenv("XVM_OFFLINE")) == "true",
+	}
+}
+
+// UseCache makes all mirrors request through the cache, nil disables caching
+func UseCache(c *Cache) {
+	cache = c
+}
+
+// Offline reports whether the offline mode is enabled
+func Offline() bool {
+	return cache != nil && cache.Offline
+}
+
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag"`
+	LastModified string    `json:"lastModified"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+	Body         []byte    `json:"body"`
+}
+
+// fetchJSON requests the api with the query and decodes the json response into result
+func fetchJSON(api string, query map[string]string, result any) error {
+	body, err := fetch(api, query)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("Failed to decode the response of %s: %w", api, err)
+	}
+	return nil
+}
+
+// fetch requests the api with the query through the cache if it is used
+func fetch(api string, query map[string]string) ([]byte, error) {
+	if cache == nil {
+		body, _, err := request(api, query, nil)
+		return body, err
+	}
+	return cache.fetch(api, query)
+}
+
+func (c *Cache) fetch(api string, query map[string]string) ([]byte, error) {
+	key := cacheKey(api, query)
+	entry := c.load(key)
+	if entry != nil && (c.Offline || time.Since(entry.FetchedAt) < c.TTL) {
+		return entry.Body, nil
+	}
+	if c.Offline {
+		return nil, fmt.Errorf("Failed to request: %s, %w", key, ErrOffline)
+	}
+	body, resp, err := request(api, query, entry)
+	if err != nil {
+		if entry == nil {
+			return nil, err
+		}
+		log.Warn().Msgf("%s, uses the cached response fetched at %s instead", err, entry.FetchedAt.Format(time.DateTime))
+		return entry.Body, nil
+	}
+	if resp.StatusCode() != http.StatusNotModified {
+		entry = &cacheEntry{
+			URL:          key,
+			ETag:         resp.Header().Get("ETag"),
+			LastModified: resp.Header().Get("Last-Modified"),
+			Body:         body,
+		}
+	}
+	entry.FetchedAt = time.Now()
+	if err := c.save(key, entry); err != nil {
+		log.Debug().Msgf("Failed to cache the response of %s: %s", key, err)
+	}
+	return entry.Body, nil
+}
+
+// request requests the api, conditionally if the cached entry is given
+func request(api string, query map[string]string, entry *cacheEntry) ([]byte, *resty.Response, error) {
+	req := resty.New().R().SetQueryParams(query)
+	if entry != nil {
+		if entry.ETag != "" {
+			req.SetHeader("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.SetHeader("If-Modified-Since", entry.LastModified)
+		}
+	}
+	resp, err := req.Get(api)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to request: %s, %w", api, err)
+	}
+	if entry != nil && resp.StatusCode() == http.StatusNotModified {
+		return entry.Body, resp, nil
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		return nil, nil, fmt.Errorf("Failed to request: %s, status: %d, %w", api, resp.StatusCode(), errNotFound)
+	}
+	if resp.IsError() {
+		return nil, nil, fmt.Errorf("Failed to request: %s, status: %d", api, resp.StatusCode())
+	}
+	return resp.Body(), resp, nil
+}
+
+func (c *Cache) load(key string) *cacheEntry {
+	data, err := os.ReadFile(c.file(key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != key {
+		return nil
+	}
+	return &entry
+}
+
+func (c *Cache) save(key string, entry *cacheEntry) error {
+	if err := os.MkdirAll(c.Path, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return tools.WriteFileAtomic(c.file(key), data)
+}
+
+func (c *Cache) file(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Path, hex.EncodeToString(sum[:])+".json")
+}
+
+func cacheKey(api string, query map[string]string) string {
+	if len(query) == 0 {
+		return api
+	}
+	values := url.Values{}
+	for k, v := range query {
+		values.Set(k, v)
+	}
+	sep := "?"
+	if strings.Contains(api, "?") {
+		sep = "&"
+	}
+	return api + sep + values.Encode()
+}
@@ -0,0 +1,119 @@
+package mirrors
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// discoMirror indexes the distributions through the foojay disco api
+type discoMirror struct {
+	API          string `json:"api"`
+	Distribution string `json:"distribution"`
+}
+
+func newDiscoMirror(name, distribution string) *distributionMirror {
+	return &distributionMirror{
+		Name: name,
+		Mirror: discoMirror{
+			API:          strings.TrimSuffix(overwriteConfig(name, "api", "https://api.foojay.io/disco/v3.0"), "/"),
+			Distribution: distribution,
+		},
+	}
+}
+
+type discoPackages struct {
+	Result []struct {
+		ID          string `json:"id"`
+		Filename    string `json:"filename"`
+		JavaVersion string `json:"java_version"`
+		Links       struct {
+			Download string `json:"pkg_download_redirect"`
+		} `json:"links"`
+	} `json:"result"`
+}
+
+type discoPackageInfo struct {
+	Result []struct {
+		Checksum     string `json:"checksum"`
+		ChecksumType string `json:"checksum_type"`
+	} `json:"result"`
+}
+
+func (m discoMirror) Versions() ([]*VersionDesc, error) {
+	query := map[string]string{
+		"distribution":     m.Distribution,
+		"operating_system": m.os(),
+		"architecture":     m.arch(),
+		"archive_type":     defaultExtension(),
+		"package_type":     "jdk",
+		"release_status":   "ga",
+		"javafx_bundled":   "false",
+	}
+	if runtime.GOOS == "linux" {
+		query["lib_c_type"] = "glibc"
+	}
+	var pkgs discoPackages
+	if err := fetchJSON(m.API+"/packages", query, &pkgs); err != nil {
+		return nil, err
+	}
+	var versions []*VersionDesc
+	for _, pkg := range pkgs.Result {
+		// 21.0.2+13 -> v21.0.2
+		version := "v" + strings.SplitN(pkg.JavaVersion, "+", 2)[0]
+		if slices.ContainsFunc(versions, func(vd *VersionDesc) bool {
+			return vd.Version == version
+		}) {
+			continue
+		}
+		versions = append(versions, &VersionDesc{
+			URL:         pkg.Links.Download,
+			Filename:    pkg.Filename,
+			Version:     version,
+			ChecksumURL: m.API + "/ids/" + pkg.ID,
+		})
+	}
+	return versions, nil
+}
+
+func (m discoMirror) BaseURL() string {
+	return m.API
+}
+
+// Checksum reads the sha256 from the package details of the disco api
+func (m discoMirror) Checksum(vd *VersionDesc) (string, error) {
+	var info discoPackageInfo
+	if err := fetchJSON(vd.ChecksumURL, nil, &info); err != nil {
+		return "", err
+	}
+	if len(info.Result) == 0 || info.Result[0].Checksum == "" {
+		return "", fmt.Errorf("no sha256 found for %s in %s", vd.Filename, vd.ChecksumURL)
+	}
+	if t := info.Result[0].ChecksumType; !strings.EqualFold(t, "sha256") {
+		return "", fmt.Errorf("unsupported checksum type %s of %s in %s", t, vd.Filename, vd.ChecksumURL)
+	}
+	return info.Result[0].Checksum, nil
+}
+
+func (m discoMirror) arch() string {
+	switch runtime.GOARCH {
+	case "amd64":
+		return "x64"
+	case "386":
+		return "x86"
+	case "arm64":
+		return "aarch64"
+	default:
+		return runtime.GOARCH
+	}
+}
+
+func (m discoMirror) os() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "macos"
+	default:
+		return runtime.GOOS
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"slices"
 	"strings"
 
-	"github.com/go-resty/resty/v2"
 	"golang.org/x/mod/semver"
 )
 
@@ -49,13 +48,19 @@ func (g *goMirror) BaseURL() string {
 	return g.BaseMirror
 }
 
+func (g *goMirror) Checksum(vd *VersionDesc) (string, error) {
+	if vd.Sha256 == "" {
+		return "", fmt.Errorf("no sha256 found for %s in %s", vd.Filename, g.API)
+	}
+	return vd.Sha256, nil
+}
+
 func (g *goMirror) listVersionMetadata() ([]goVersionMetadata, error) {
 	var versions []goVersionMetadata
-	_, err := resty.New().R().SetResult(&versions).Get(g.API)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to request: %s, %w", g.API, err)
+	if err := fetchJSON(g.API, nil, &versions); err != nil {
+		return nil, err
 	}
-	slices.DeleteFunc(versions, func(metadata goVersionMetadata) bool {
+	versions = slices.DeleteFunc(versions, func(metadata goVersionMetadata) bool {
 		return !metadata.Stable
 	})
 	return versions, nil
@@ -2,38 +2,49 @@ package mirrors
 
 import (
 	"fmt"
-	"os"
 	"runtime"
 	"slices"
 	"strconv"
 	"strings"
 
-	"github.com/go-resty/resty/v2"
 	"github.com/rs/zerolog/log"
 )
 
 const (
-	java = "java"
-	zulu = "zulu"
+	java     = "java"
+	zulu     = "zulu"
+	temurin  = "temurin"
+	corretto = "corretto"
+	liberica = "liberica"
+	graalvm  = "graalvm"
 )
 
-func Java() Mirror {
+// Java returns the mirror of the distribution, the default one is used if it is empty or unknown
+func Java(distribution string) Mirror {
 	mirrors := []*distributionMirror{
 		newZuluMirror(),
+		newTemurinMirror(),
+		newDiscoMirror(corretto, "corretto"),
+		newDiscoMirror(liberica, "liberica"),
+		newDiscoMirror(graalvm, "graalvm_community"),
 	}
-	distribution := os.Getenv("XVM_JAVA_DISTRIBUTION")
 	if distribution == "" {
-		distribution = defaultDistribution()
+		distribution = DefaultJavaDistribution()
 	}
 	dm := getDistributionMirror(mirrors, distribution)
 	if dm == nil {
-		log.Error().Msgf("unknown distribution: %s, uses %s instead.", distribution, defaultDistribution())
-		dm = getDistributionMirror(mirrors, defaultDistribution())
+		log.Error().Msgf("unknown distribution: %s, uses %s instead.", distribution, DefaultJavaDistribution())
+		dm = getDistributionMirror(mirrors, DefaultJavaDistribution())
 	}
 	return dm.Mirror
 }
 
-func defaultDistribution() string {
+// JavaDistributions returns the names of all supported java distributions
+func JavaDistributions() []string {
+	return []string{zulu, temurin, corretto, liberica, graalvm}
+}
+
+func DefaultJavaDistribution() string {
 	return zulu
 }
 
@@ -61,7 +72,7 @@ func newZuluMirror() *distributionMirror {
 	return &distributionMirror{
 		Name: zulu,
 		Mirror: zuluMirror{
-			API:  overwriteConfig(java, "api", "https://api.azul.com/zulu/download/community/v1.0/bundles/"),
+			API:  overwriteConfig(zulu, "api", "https://api.azul.com/zulu/download/community/v1.0/bundles/"),
 			Base: overwriteMirror(java, "https://cdn.azul.com/zulu/bin/"),
 		},
 	}
@@ -75,9 +86,10 @@ func (m zuluMirror) Versions() ([]*VersionDesc, error) {
 	versions := make([]*VersionDesc, 0, len(metadata))
 	for _, v := range metadata {
 		versions = append(versions, &VersionDesc{
-			URL:      v.Url,
-			Filename: v.Name,
-			Version:  v.String(),
+			URL:         v.Url,
+			Filename:    v.Name,
+			Version:     v.String(),
+			ChecksumURL: m.API + strconv.Itoa(v.ID) + "/",
 		})
 	}
 	return versions, nil
@@ -87,12 +99,29 @@ func (m zuluMirror) BaseURL() string {
 	return m.Base
 }
 
+// Checksum reads the sha256 from the bundle details of the azul api
+func (m zuluMirror) Checksum(vd *VersionDesc) (string, error) {
+	var bundle zuluBundle
+	if err := fetchJSON(vd.ChecksumURL, nil, &bundle); err != nil {
+		return "", err
+	}
+	if bundle.Sha256 == "" {
+		return "", fmt.Errorf("no sha256 found for %s in %s", vd.Filename, vd.ChecksumURL)
+	}
+	return bundle.Sha256, nil
+}
+
+type zuluBundle struct {
+	Sha256 string `json:"sha256_hash"`
+}
+
 type archOpts struct {
 	Arch string
 	Bit  string
 }
 
 type versionMetadata struct {
+	ID      int    `json:"id"`
 	Version []int  `json:"java_version"`
 	Url     string `json:"url"`
 	Name    string `json:"name"`
@@ -109,7 +138,7 @@ func (v versionMetadata) String() string {
 func (m zuluMirror) usableVersionMetadata() ([]versionMetadata, error) {
 	arch := m.arch()
 	var versions []versionMetadata
-	resp, err := resty.New().R().SetQueryParams(map[string]string{
+	err := fetchJSON(m.API, map[string]string{
 		"os":             m.os(),
 		"ext":            defaultExtension(),
 		"bundle_type":    "jdk",
@@ -117,10 +146,9 @@ func (m zuluMirror) usableVersionMetadata() ([]versionMetadata, error) {
 		"hw_bitness":     arch.Bit,
 		"release_status": "ga",
 		"javafx":         "false",
-	}).SetResult(&versions).Get(m.API)
+	}, &versions)
 	if err != nil {
-		fmt.Println(resp)
-		return nil, fmt.Errorf("Failed to request: %s, %w", m.API, err)
+		return nil, err
 	}
 	return versions, nil
 }
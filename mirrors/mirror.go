@@ -2,11 +2,16 @@ package mirrors
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/modern-devops/xvm/tools"
+
+	"github.com/go-resty/resty/v2"
 )
 
 const (
@@ -15,15 +20,46 @@ const (
 )
 
 type VersionDesc struct {
-	URL      string `json:"url"`
-	Filename string `json:"filename"`
-	Version  string `json:"version"`
-	Sha256   string `json:"sha256"`
+	URL         string `json:"url"`
+	Filename    string `json:"filename"`
+	Version     string `json:"version"`
+	Sha256      string `json:"sha256"`
+	ChecksumURL string `json:"checksumUrl,omitempty"`
+	// LTS is the codename of a long-term support release, empty if it is not one
+	LTS string `json:"lts,omitempty"`
 }
 
 type Mirror interface {
 	Versions() ([]*VersionDesc, error)
 	BaseURL() string
+	// Checksum returns the trusted sha256 digest of the version's archive,
+	// an empty digest means the mirror does not publish any
+	Checksum(vd *VersionDesc) (string, error)
+}
+
+// Reachable checks whether the host of the mirror answers within the timeout, any status but a server error is fine
+func Reachable(m Mirror, timeout time.Duration) error {
+	u, err := url.Parse(m.BaseURL())
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid mirror: %s", m.BaseURL())
+	}
+	host := u.Scheme + "://" + u.Host
+	resp, err := resty.New().SetTimeout(timeout).R().Head(host)
+	if err != nil {
+		return fmt.Errorf("Failed to request: %s, %w", host, err)
+	}
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return fmt.Errorf("Failed to request: %s, status: %d", host, resp.StatusCode())
+	}
+	return nil
+}
+
+// overrides maps the names of the sdks to their overridden configs, such as mirror and api
+var overrides map[string]map[string]string
+
+// UseOverrides overrides the configs of the mirrors, the environment variables still take precedence
+func UseOverrides(o map[string]map[string]string) {
+	overrides = o
 }
 
 func overwriteMirror(sdk, mirror string) string {
@@ -38,6 +74,9 @@ func overwriteConfig(sdk, key, value string) string {
 	if m := os.Getenv(strings.ToUpper(fmt.Sprintf("XVM_%s_%s", sdk, key))); m != "" {
 		return m
 	}
+	if m := overrides[sdk][key]; m != "" {
+		return m
+	}
 	return value
 }
 
@@ -1,13 +1,19 @@
 package mirrors
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"runtime"
 	"slices"
-
-	"github.com/go-resty/resty/v2"
+	"strings"
 )
 
-const node = "node"
+const (
+	node    = "node"
+	shasums = "SHASUMS256.txt"
+)
 
 type nodeMirror struct {
 	BaseMirror string `json:"base"`
@@ -23,8 +29,7 @@ func Node() Mirror {
 
 func (n *nodeMirror) Versions() ([]*VersionDesc, error) {
 	var nvs []nodeVersion
-	_, err := resty.New().R().SetResult(&nvs).Get(n.API)
-	if err != nil {
+	if err := fetchJSON(n.API, nil, &nvs); err != nil {
 		return nil, err
 	}
 	var versions []*VersionDesc
@@ -38,9 +43,11 @@ func (n *nodeMirror) Versions() ([]*VersionDesc, error) {
 			continue
 		}
 		versions = append(versions, &VersionDesc{
-			URL:      n.BaseMirror + "/" + nv.Version + "/" + filename,
-			Filename: filename,
-			Version:  nv.Version,
+			URL:         n.BaseMirror + "/" + nv.Version + "/" + filename,
+			Filename:    filename,
+			Version:     nv.Version,
+			ChecksumURL: n.BaseMirror + "/" + nv.Version + "/" + shasums,
+			LTS:         string(nv.LTS),
 		})
 	}
 	return versions, nil
@@ -50,6 +57,22 @@ func (n *nodeMirror) BaseURL() string {
 	return n.BaseMirror
 }
 
+// Checksum looks up the archive in the SHASUMS256.txt published with every release
+func (n *nodeMirror) Checksum(vd *VersionDesc) (string, error) {
+	body, err := fetch(vd.ChecksumURL, nil)
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == vd.Filename {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("no sha256 found for %s in %s", vd.Filename, vd.ChecksumURL)
+}
+
 type nodeFile string
 
 type files []*nodeFile
@@ -96,8 +119,22 @@ func (f nodeFile) VersionFilename(version string) string {
 }
 
 type nodeVersion struct {
-	Version string `json:"version"`
-	Files   files  `json:"files"`
+	Version string  `json:"version"`
+	Files   files   `json:"files"`
+	LTS     nodeLTS `json:"lts"`
+}
+
+// nodeLTS is the codename of a long-term support release, which is false in the index for the others
+type nodeLTS string
+
+func (l *nodeLTS) UnmarshalJSON(data []byte) error {
+	var codename string
+	if err := json.Unmarshal(data, &codename); err != nil {
+		*l = ""
+		return nil
+	}
+	*l = nodeLTS(codename)
+	return nil
 }
 
 var nfm = map[string]nodeOsArchDesc{
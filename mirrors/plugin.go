@@ -0,0 +1,248 @@
+package mirrors
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	pluginVersionsJSON = "json"
+	pluginVersionsHTML = "html"
+	pluginChecksumSums = "sums"
+	pluginChecksumFile = "file"
+)
+
+// PluginOptions describes the mirror of a plugin sdk defined by a config file,
+// the url templates may contain {version}, {os}, {arch} and {ext}
+type PluginOptions struct {
+	// URL is the template of the archive url
+	URL string `yaml:"url"`
+	// OS, Arch and Ext map runtime.GOOS, runtime.GOARCH and runtime.GOOS to the values in the url,
+	// GOOS and GOARCH are used if unmapped, the ext defaults to zip on windows and tar.gz on the others
+	OS       map[string]string `yaml:"os"`
+	Arch     map[string]string `yaml:"arch"`
+	Ext      map[string]string `yaml:"ext"`
+	Versions PluginVersions    `yaml:"versions"`
+	Checksum *PluginChecksum   `yaml:"checksum"`
+}
+
+// PluginVersions describes where the available versions are listed
+type PluginVersions struct {
+	URL string `yaml:"url"`
+	// Type is json for an api, whose versions are selected by Path, or html for a directory listing
+	Type string `yaml:"type"`
+	// Path selects the versions of a json document, such as [*].version or releases[*].tag_name,
+	// [*] selects all items of an array and * all values of an object
+	Path string `yaml:"path"`
+	// Pattern extracts the version by the first group, it is required for html
+	Pattern string `yaml:"pattern"`
+}
+
+// PluginChecksum describes where the sha256 digests are published
+type PluginChecksum struct {
+	URL string `yaml:"url"`
+	// Type is sums for lines of `<digest>  <filename>`, or file for a digest of the archive only
+	Type string `yaml:"type"`
+}
+
+type pluginMirror struct {
+	opts    *PluginOptions
+	pattern *regexp.Regexp
+}
+
+func Plugin(opts *PluginOptions) (Mirror, error) {
+	if opts.URL == "" || opts.Versions.URL == "" {
+		return nil, fmt.Errorf("both url and versions.url are required")
+	}
+	m := &pluginMirror{opts: opts}
+	switch opts.Versions.Type {
+	case pluginVersionsJSON:
+		if opts.Versions.Path == "" {
+			return nil, fmt.Errorf("versions.path is required for %s", pluginVersionsJSON)
+		}
+	case pluginVersionsHTML:
+		if opts.Versions.Pattern == "" {
+			return nil, fmt.Errorf("versions.pattern is required for %s", pluginVersionsHTML)
+		}
+	default:
+		return nil, fmt.Errorf("unknown versions.type: %s, allows %s,%s", opts.Versions.Type, pluginVersionsJSON, pluginVersionsHTML)
+	}
+	if opts.Versions.Pattern != "" {
+		pattern, err := regexp.Compile(opts.Versions.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid versions.pattern: %w", err)
+		}
+		if pattern.NumSubexp() < 1 {
+			return nil, fmt.Errorf("versions.pattern requires a group to extract the version")
+		}
+		m.pattern = pattern
+	}
+	if c := opts.Checksum; c != nil && c.Type != pluginChecksumSums && c.Type != pluginChecksumFile {
+		return nil, fmt.Errorf("unknown checksum.type: %s, allows %s,%s", c.Type, pluginChecksumSums, pluginChecksumFile)
+	}
+	return m, nil
+}
+
+func (m *pluginMirror) Versions() ([]*VersionDesc, error) {
+	body, err := fetch(m.opts.Versions.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var raws []string
+	if m.opts.Versions.Type == pluginVersionsJSON {
+		var doc any
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return nil, fmt.Errorf("Failed to decode the response of %s: %w", m.opts.Versions.URL, err)
+		}
+		raws = selectJSON(doc, m.opts.Versions.Path)
+	} else {
+		for _, match := range m.pattern.FindAllStringSubmatch(string(body), -1) {
+			raws = append(raws, match[1])
+		}
+	}
+	var versions []*VersionDesc
+	for _, raw := range raws {
+		version := m.extract(raw)
+		if version == "" || slices.ContainsFunc(versions, func(vd *VersionDesc) bool { return vd.Version == "v"+version }) {
+			continue
+		}
+		url := m.expand(m.opts.URL, version)
+		vd := &VersionDesc{
+			URL:      url,
+			Filename: path.Base(url),
+			Version:  "v" + version,
+		}
+		if m.opts.Checksum != nil {
+			vd.ChecksumURL = m.expand(m.opts.Checksum.URL, version)
+		}
+		versions = append(versions, vd)
+	}
+	return versions, nil
+}
+
+func (m *pluginMirror) BaseURL() string {
+	return m.opts.URL
+}
+
+// Checksum returns an empty digest if the plugin does not define where the digests are published
+func (m *pluginMirror) Checksum(vd *VersionDesc) (string, error) {
+	if m.opts.Checksum == nil {
+		return "", nil
+	}
+	body, err := fetch(vd.ChecksumURL, nil)
+	if err != nil {
+		return "", err
+	}
+	if m.opts.Checksum.Type == pluginChecksumFile {
+		if fields := strings.Fields(string(body)); len(fields) > 0 {
+			return fields[0], nil
+		}
+		return "", fmt.Errorf("no sha256 found in %s", vd.ChecksumURL)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sha256sum marks files read in binary mode with *
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == vd.Filename {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("no sha256 found for %s in %s", vd.Filename, vd.ChecksumURL)
+}
+
+// extract returns the version without the v prefix, or empty if it does not match the pattern
+func (m *pluginMirror) extract(raw string) string {
+	if m.pattern != nil && m.opts.Versions.Type == pluginVersionsJSON {
+		match := m.pattern.FindStringSubmatch(raw)
+		if match == nil {
+			return ""
+		}
+		raw = match[1]
+	}
+	return strings.TrimPrefix(strings.TrimSpace(raw), "v")
+}
+
+func (m *pluginMirror) expand(template, version string) string {
+	ext, ok := m.opts.Ext[runtime.GOOS]
+	if !ok {
+		ext = defaultExtension()
+	}
+	return strings.NewReplacer(
+		"{version}", version,
+		"{os}", mapped(m.opts.OS, runtime.GOOS),
+		"{arch}", mapped(m.opts.Arch, runtime.GOARCH),
+		"{ext}", ext,
+	).Replace(template)
+}
+
+func mapped(m map[string]string, key string) string {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	return key
+}
+
+// selectJSON returns the strings and numbers selected by the path from the json document
+func selectJSON(doc any, path string) []string {
+	nodes := []any{doc}
+	for _, segment := range splitJSONPath(path) {
+		var next []any
+		for _, node := range nodes {
+			switch v := node.(type) {
+			case []any:
+				if segment == "[*]" {
+					next = append(next, v...)
+				}
+			case map[string]any:
+				if segment == "*" {
+					for _, item := range v {
+						next = append(next, item)
+					}
+				} else if item, ok := v[segment]; ok {
+					next = append(next, item)
+				}
+			}
+		}
+		nodes = next
+	}
+	var values []string
+	for _, node := range nodes {
+		switch v := node.(type) {
+		case string:
+			values = append(values, v)
+		case float64:
+			values = append(values, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+	return values
+}
+
+// splitJSONPath splits paths like releases[*].tag_name into releases, [*] and tag_name
+func splitJSONPath(path string) []string {
+	var segments []string
+	for _, field := range strings.Split(path, ".") {
+		for field != "" {
+			i := strings.Index(field, "[*]")
+			switch {
+			case i == -1:
+				segments = append(segments, field)
+				field = ""
+			case i == 0:
+				segments = append(segments, "[*]")
+				field = field[3:]
+			default:
+				segments = append(segments, field[:i])
+				field = field[i:]
+			}
+		}
+	}
+	return segments
+}
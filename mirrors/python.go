@@ -0,0 +1,128 @@
+package mirrors
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	python        = "python"
+	pythonPages   = 3
+	pythonPerPage = 100
+)
+
+// pythonAsset matches the relocatable install_only archives of python-build-standalone,
+// such as cpython-3.12.1+20240107-x86_64-unknown-linux-gnu-install_only.tar.gz
+var pythonAsset = regexp.MustCompile(`^cpython-(\d+\.\d+\.\d+)\+(\d+)-(.+)-install_only\.tar\.gz$`)
+
+type pythonMirror struct {
+	BaseMirror string `json:"base"`
+	API        string `json:"api"`
+}
+
+func Python() Mirror {
+	return &pythonMirror{
+		BaseMirror: overwriteMirror(python, "https://github.com/indygreg/python-build-standalone/releases/download"),
+		API:        overwriteAPI(python, "https://api.github.com/repos/indygreg/python-build-standalone/releases"),
+	}
+}
+
+type pythonRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+	} `json:"assets"`
+}
+
+func (p *pythonMirror) Versions() ([]*VersionDesc, error) {
+	triples := pythonTriples()
+	// the same version is built by many releases, the latest build is picked
+	builds := map[string]int{}
+	var versions []*VersionDesc
+	for page := 1; page <= pythonPages; page++ {
+		var releases []pythonRelease
+		err := fetchJSON(p.API, map[string]string{
+			"per_page": strconv.Itoa(pythonPerPage),
+			"page":     strconv.Itoa(page),
+		}, &releases)
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			for _, asset := range release.Assets {
+				m := pythonAsset.FindStringSubmatch(asset.Name)
+				if m == nil || !slices.Contains(triples, m[3]) {
+					continue
+				}
+				version, build := "v"+m[1], atoi(m[2])
+				if b, ok := builds[version]; ok && b >= build {
+					continue
+				}
+				builds[version] = build
+				vd := &VersionDesc{
+					URL:         p.BaseMirror + "/" + release.TagName + "/" + asset.Name,
+					Filename:    asset.Name,
+					Version:     version,
+					ChecksumURL: p.BaseMirror + "/" + release.TagName + "/SHA256SUMS",
+				}
+				i := slices.IndexFunc(versions, func(vd *VersionDesc) bool {
+					return vd.Version == version
+				})
+				if i != -1 {
+					versions[i] = vd
+				} else {
+					versions = append(versions, vd)
+				}
+			}
+		}
+		if len(releases) < pythonPerPage {
+			break
+		}
+	}
+	return versions, nil
+}
+
+func (p *pythonMirror) BaseURL() string {
+	return p.BaseMirror
+}
+
+// Checksum looks up the archive in the SHA256SUMS published with every release
+func (p *pythonMirror) Checksum(vd *VersionDesc) (string, error) {
+	body, err := fetch(vd.ChecksumURL, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(body), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == vd.Filename {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("no sha256 found for %s in %s", vd.Filename, vd.ChecksumURL)
+}
+
+// pythonTriples returns the target triples of the current machine, the preferred one first
+func pythonTriples() []string {
+	arch := map[string]string{"amd64": "x86_64", "arm64": "aarch64", "386": "i686"}[runtime.GOARCH]
+	if arch == "" {
+		arch = runtime.GOARCH
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{arch + "-apple-darwin"}
+	case "windows":
+		// named with -shared before 2023
+		return []string{arch + "-pc-windows-msvc", arch + "-pc-windows-msvc-shared"}
+	default:
+		return []string{arch + "-unknown-linux-gnu"}
+	}
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
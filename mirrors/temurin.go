@@ -0,0 +1,133 @@
+package mirrors
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+)
+
+// temurinPerPage is the largest page size the adoptium api allows
+const temurinPerPage = 20
+
+type temurinMirror struct {
+	API string `json:"api"`
+}
+
+func newTemurinMirror() *distributionMirror {
+	return &distributionMirror{
+		Name: temurin,
+		Mirror: temurinMirror{
+			API: overwriteConfig(temurin, "api", "https://api.adoptium.net/v3/assets/version/%5B1.0%2C100.0%5D"),
+		},
+	}
+}
+
+type temurinRelease struct {
+	Binaries []struct {
+		Package struct {
+			Name     string `json:"name"`
+			Link     string `json:"link"`
+			Checksum string `json:"checksum"`
+		} `json:"package"`
+	} `json:"binaries"`
+	VersionData struct {
+		Major    int `json:"major"`
+		Minor    int `json:"minor"`
+		Security int `json:"security"`
+		Patch    int `json:"patch"`
+	} `json:"version_data"`
+}
+
+func (r temurinRelease) String() string {
+	v := r.VersionData
+	version := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Security)
+	if v.Patch > 0 {
+		version += "." + strconv.Itoa(v.Patch)
+	}
+	return version
+}
+
+func (m temurinMirror) Versions() ([]*VersionDesc, error) {
+	var versions []*VersionDesc
+	// every page is listed, so that the old releases, such as the ones of 8 and 11, are resolvable as well
+	for page := 0; ; page++ {
+		var releases []temurinRelease
+		err := fetchJSON(m.API, map[string]string{
+			"os":           m.os(),
+			"architecture": m.arch(),
+			"image_type":   "jdk",
+			"jvm_impl":     "hotspot",
+			"release_type": "ga",
+			"vendor":       "eclipse",
+			"project":      "jdk",
+			"heap_size":    "normal",
+			"sort_order":   "DESC",
+			"page_size":    strconv.Itoa(temurinPerPage),
+			"page":         strconv.Itoa(page),
+		}, &releases)
+		if err != nil {
+			// the api responds 404 once the pages run out
+			if page > 0 && errors.Is(err, errNotFound) {
+				break
+			}
+			return nil, err
+		}
+		listed := len(versions)
+		for _, release := range releases {
+			version := release.String()
+			if len(release.Binaries) == 0 || slices.ContainsFunc(versions, func(vd *VersionDesc) bool {
+				return vd.Version == version
+			}) {
+				continue
+			}
+			pkg := release.Binaries[0].Package
+			versions = append(versions, &VersionDesc{
+				URL:      pkg.Link,
+				Filename: pkg.Name,
+				Version:  version,
+				Sha256:   pkg.Checksum,
+			})
+		}
+		// a page listing nothing new means the api ignores the paging
+		if len(releases) < temurinPerPage || len(versions) == listed {
+			break
+		}
+	}
+	return versions, nil
+}
+
+func (m temurinMirror) BaseURL() string {
+	return m.API
+}
+
+// Checksum returns the sha256 listed along with the package by the adoptium api
+func (m temurinMirror) Checksum(vd *VersionDesc) (string, error) {
+	if vd.Sha256 == "" {
+		return "", fmt.Errorf("no sha256 found for %s", vd.Filename)
+	}
+	return vd.Sha256, nil
+}
+
+func (m temurinMirror) arch() string {
+	switch runtime.GOARCH {
+	case "amd64":
+		return "x64"
+	case "386":
+		return "x86"
+	case "arm64":
+		return "aarch64"
+	default:
+		return runtime.GOARCH
+	}
+}
+
+func (m temurinMirror) os() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "mac"
+	default:
+		return runtime.GOOS
+	}
+}
@@ -0,0 +1,61 @@
+package mirrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestTemurinVersionsListsEveryPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		releases int
+	}{
+		{"short last page", 2*temurinPerPage + 5},
+		{"404 after full pages", 15 * temurinPerPage},
+	}
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			first, last := page*temurinPerPage, min((page+1)*temurinPerPage, tt.releases)
+			if first >= last {
+				http.NotFound(w, r)
+				return
+			}
+			var releases []map[string]any
+			for i := first; i < last; i++ {
+				releases = append(releases, map[string]any{
+					"binaries": []any{map[string]any{"package": map[string]any{
+						"name":     fmt.Sprintf("jdk-%d.tar.gz", i),
+						"link":     "https://example.com/jdk.tar.gz",
+						"checksum": "abc",
+					}}},
+					"version_data": map[string]int{"major": 8 + i/100, "minor": 0, "security": i % 100},
+				})
+			}
+			_ = json.NewEncoder(w).Encode(releases)
+		}))
+		versions, err := temurinMirror{API: server.URL}.Versions()
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: Versions failed: %s", tt.name, err)
+			continue
+		}
+		if len(versions) != tt.releases {
+			t.Errorf("%s: Versions listed %d releases, want %d", tt.name, len(versions), tt.releases)
+		}
+	}
+}
+
+func TestTemurinVersionsFailsOnFirstPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	if _, err := (temurinMirror{API: server.URL}).Versions(); err == nil {
+		t.Errorf("Versions succeeded, want an error")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/modern-devops/xvm/mirrors"
 	"github.com/modern-devops/xvm/sdks"
 	"github.com/modern-devops/xvm/tools"
+	"github.com/modern-devops/xvm/tools/manifest"
 
 	"golang.org/x/mod/modfile"
 )
@@ -18,8 +19,11 @@ const (
 	bin           = "bin"
 	goroot        = "GOROOT"
 	gopath        = "GOPATH"
+	gotoolchain   = "GOTOOLCHAIN"
+	gowork        = "GOWORK"
 	goVersionFile = ".goversion"
 	goModFile     = "go.mod"
+	goWorkFile    = "go.work"
 )
 
 type gvm struct {
@@ -40,60 +44,71 @@ func (g *gvm) Info() *sdks.SdkInfo {
 				Path: filepath.Join(bin, tools.CommandFile(golang)),
 			},
 		},
-		BinPaths: []string{filepath.Join(goPath, bin)},
-		Mirror:   mirrors.Go(),
+		BinPaths:    []string{filepath.Join(goPath, bin)},
+		Mirror:      mirrors.Go(),
+		VersionFile: goVersionFile,
 		WithEnvs: func(wp string) []string {
-			return []string{goroot + "=" + wp, gopath + "=" + goPath}
+			envs := []string{goroot + "=" + wp, gopath + "=" + goPath}
+			// the go command must not switch to another toolchain than the one chosen by xvm
+			if os.Getenv(gotoolchain) == "" {
+				envs = append(envs, gotoolchain+"=local")
+			}
+			return envs
 		},
 	}
 }
 
 // Version try to detect the go version
-func (g *gvm) Version() (string, error) {
-	// 1. detect from .goversion
-	vfs, err := tools.DetectVersionFiles(goVersionFile)
-	if err != nil {
-		return "", err
+func (g *gvm) Version(wd string) (*tools.Version, error) {
+	// 1. detect from .xvm.toml, .goversion and .tool-versions
+	v, err := tools.DetectVersion(wd, manifest.VersionFile(golang), &tools.VersionFile{Name: goVersionFile}, tools.ToolVersions(golang))
+	if err != nil || v.Version != "" {
+		return v, err
 	}
-	for _, vf := range vfs {
-		if _, err := os.Stat(vf); err != nil {
+	// 2. detect from go.work
+	for _, gw := range detectGoWorks(wd) {
+		if _, err := os.Stat(gw); err != nil {
+			v.Miss(gw)
 			continue
 		}
-		data, err := os.ReadFile(vf)
+		gv, err := getGoWorkVersion(gw)
 		if err != nil {
-			return "", err
+			return v, err
 		}
-		return strings.TrimSpace(string(data)), nil
-	}
-	// 2. detect from go.mod
-	gms, err := detectGoMods()
-	if err != nil {
-		return "", err
+		v.Check(gw, gv)
+		return v, nil
 	}
+	// 3. detect from go.mod
+	gms := detectGoMods(wd)
 	for _, gm := range gms {
 		if _, err := os.Stat(gm); err != nil {
+			v.Miss(gm)
 			continue
 		}
-		v, err := getGoModuleVersion(gm)
+		gv, err := getGoModuleVersion(gm)
 		if err != nil {
-			return "", err
+			return v, err
 		}
+		v.Check(gm, gv)
 		return v, nil
 	}
-	return "", nil
+	return v, nil
 }
 
-func detectGoMods() ([]string, error) {
-	wd, err := os.Getwd()
-	if err != nil {
-		return nil, err
-	}
-	var gms []string
-	gms = append(gms, filepath.Join(wd, goModFile))
-	if rp := tools.GitRootPath(wd); wd != rp {
-		gms = append(gms, filepath.Join(rp, goModFile))
+// detectGoWorks returns the candidates of the go.work, which is disabled by GOWORK=off or specified by GOWORK
+func detectGoWorks(wd string) []string {
+	switch gw := os.Getenv(gowork); gw {
+	case "off":
+		return nil
+	case "":
+		return tools.DetectFiles(wd, goWorkFile)
+	default:
+		return []string{gw}
 	}
-	return gms, nil
+}
+
+func detectGoMods(wd string) []string {
+	return tools.DetectFiles(wd, goModFile)
 }
 
 func getGoModuleVersion(gm string) (string, error) {
@@ -105,8 +120,32 @@ func getGoModuleVersion(gm string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("parse %s failed, %w", gm, err)
 	}
-	if strings.Count(f.Go.Version, ".") == 1 {
-		return f.Go.Version + ".0", nil
+	return goVersion(f.Go, f.Toolchain), nil
+}
+
+func getGoWorkVersion(gw string) (string, error) {
+	data, err := os.ReadFile(gw)
+	if err != nil {
+		return "", err
+	}
+	f, err := modfile.ParseWork(gw, data, nil)
+	if err != nil {
+		return "", fmt.Errorf("parse %s failed, %w", gw, err)
+	}
+	return goVersion(f.Go, f.Toolchain), nil
+}
+
+// goVersion returns the version of the toolchain directive, such as go1.21.5, or the go directive if there is none
+func goVersion(g *modfile.Go, t *modfile.Toolchain) string {
+	// toolchain default means the toolchain of the go directive
+	if t != nil && t.Name != "default" {
+		return strings.TrimPrefix(t.Name, "go")
+	}
+	if g == nil {
+		return ""
+	}
+	if strings.Count(g.Version, ".") == 1 {
+		return g.Version + ".0"
 	}
-	return f.Go.Version, nil
+	return g.Version
 }
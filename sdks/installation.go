@@ -0,0 +1,254 @@
+package sdks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modern-devops/xvm/mirrors"
+	"github.com/modern-devops/xvm/tools"
+	"github.com/modern-devops/xvm/tools/filelock"
+
+	"github.com/google/uuid"
+)
+
+const (
+	doneFile  = ".done"
+	usageFile = ".usage"
+)
+
+// Installation is the metadata recorded in the .done file of an installed version
+type Installation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Qualifier is the qualifier of the version, such as the distribution of java, empty if it is not qualified
+	Qualifier   string    `json:"qualifier,omitempty"`
+	URL         string    `json:"url"`
+	Sha256      string    `json:"sha256"`
+	InstalledAt time.Time `json:"installedAt"`
+	Tools       []Tool    `json:"tools"`
+	BinPaths    []string  `json:"binPaths"`
+	Root        string    `json:"root"`
+}
+
+// Usage records when and by which projects an installed version is used
+type Usage struct {
+	LastUsed time.Time `json:"lastUsed"`
+	Projects []string  `json:"projects"`
+}
+
+// Installations returns all installed versions of the sdk
+func (i *UserIsolatedInstaller) Installations(sdk Sdk) ([]*Installation, error) {
+	sp := filepath.Join(i.SdkStashPath, sdk.Info().Name)
+	entries, err := os.ReadDir(sp)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var installations []*Installation
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		in, err := readInstallation(filepath.Join(sp, entry.Name()))
+		if err != nil {
+			// not installed completely
+			continue
+		}
+		if in.Version == "" {
+			in.Version = entry.Name()
+		}
+		in.Qualifier = qualifierOfDir(sdk, in.Version)
+		// installed before the sdk was qualified
+		if QualifierOf(sdk, in.Version) == "" && in.Qualifier != "" {
+			in.Version = Qualified(in.Qualifier, in.Version)
+		}
+		installations = append(installations, in)
+	}
+	return installations, nil
+}
+
+// Incomplete returns the root paths of the versions of the sdk left without the .done file,
+// such as ones installed by an older xvm that was interrupted
+func (i *UserIsolatedInstaller) Incomplete(sdk Sdk) ([]string, error) {
+	sp := filepath.Join(i.SdkStashPath, sdk.Info().Name)
+	entries, err := os.ReadDir(sp)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var roots []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		root := filepath.Join(sp, entry.Name())
+		if _, err := os.Stat(filepath.Join(root, doneFile)); err != nil {
+			roots = append(roots, root)
+		}
+	}
+	return roots, nil
+}
+
+func readInstallation(root string) (*Installation, error) {
+	df := filepath.Join(root, doneFile)
+	fi, err := os.Stat(df)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(df)
+	if err != nil {
+		return nil, err
+	}
+	var in Installation
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+	// installed by an older xvm, which only recorded the sdk info
+	if in.InstalledAt.IsZero() {
+		in.InstalledAt = fi.ModTime()
+	}
+	in.Root = root
+	return &in, nil
+}
+
+// DiskUsage returns the total size of the files of the installation
+func (in *Installation) DiskUsage() (int64, error) {
+	var size int64
+	err := filepath.WalkDir(in.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += fi.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// Usage returns the usage of the installation, which is empty if it has never been used
+func (in *Installation) Usage() (*Usage, error) {
+	return readUsage(in.Root)
+}
+
+func readUsage(root string) (*Usage, error) {
+	data, err := os.ReadFile(filepath.Join(root, usageFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Usage{}, nil
+		}
+		return nil, err
+	}
+	var usage Usage
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// recordUsage records the usage of the installation at root, the project is
+// only recorded if the version is defined by it rather than a fallback to the latest one
+func recordUsage(root string, project string) error {
+	usage, err := readUsage(root)
+	if err != nil {
+		usage = &Usage{}
+	}
+	usage.LastUsed = time.Now()
+	if project != "" && !slices.Contains(usage.Projects, project) {
+		usage.Projects = append(usage.Projects, project)
+	}
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return err
+	}
+	return tools.WriteFileAtomic(filepath.Join(root, usageFile), data)
+}
+
+func currentProject() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return tools.GitRootPath(wd)
+}
+
+func (i *UserIsolatedInstaller) done(d *SdkInfo, vd *mirrors.VersionDesc, sha256, version, root, df string) error {
+	data, err := json.Marshal(&Installation{
+		Name:        d.Name,
+		Version:     version,
+		URL:         vd.URL,
+		Sha256:      sha256,
+		InstalledAt: time.Now(),
+		Tools:       d.Tools,
+		BinPaths:    d.BinPaths,
+		Root:        root,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(df, data, 0644)
+}
+
+// Uninstall removes the installed version of the sdk, a version being installed by another process is refused
+func (i *UserIsolatedInstaller) Uninstall(sdk Sdk, version string) error {
+	name := sdk.Info().Name
+	if err := checkVersionName(version); err != nil {
+		return err
+	}
+	root := filepath.Join(i.SdkStashPath, name, version)
+	if _, err := os.Stat(root); err != nil {
+		// an unqualified version is of the default qualifier, such as 21.0.2 for zulu-21.0.2 of java
+		version = Qualified(Qualify(sdk, version))
+		if err := checkVersionName(version); err != nil {
+			return err
+		}
+		root = i.versionRoot(sdk, version)
+		if _, err := os.Stat(root); err != nil {
+			return fmt.Errorf("%s@v%s is not installed", name, version)
+		}
+	}
+	lock, err := filelock.TryAcquire(i.lockFile(name, version))
+	if errors.Is(err, filelock.ErrLocked) {
+		return fmt.Errorf("%s@v%s is being installed by another xvm process (%s)", name, version, filelock.ReadOwner(i.lockFile(name, version)))
+	}
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+	// move it out of the sdk root path first, so that an interrupted removal never looks installed
+	vsp := filepath.Join(i.StagingPath, name, version)
+	if err := os.MkdirAll(vsp, 0755); err != nil {
+		return fmt.Errorf("Failed to make dir: [%s], Please check: %w", vsp, err)
+	}
+	defer func() {
+		_ = os.RemoveAll(vsp)
+	}()
+	if err := os.Rename(root, filepath.Join(vsp, uuid.New().String())); err != nil {
+		return fmt.Errorf("Failed to remove dir: [%s], Please check: %w", root, err)
+	}
+	return nil
+}
+
+// checkVersionName checks whether the version is a single path element, which names the directory of the
+// installation, so that it never refers to a path outside the directory of the sdk, such as ../go
+func checkVersionName(version string) error {
+	if version == "" || version == "." || version == ".." || strings.ContainsAny(version, `/\`) || !filepath.IsLocal(version) {
+		return fmt.Errorf("invalid version: %s", version)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package java
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	pomFile          = "pom.xml"
+	gradleFile       = "build.gradle"
+	gradleKotlinFile = "build.gradle.kts"
+)
+
+var (
+	gradleToolchain     = regexp.MustCompile(`JavaLanguageVersion\.of\(\s*["']?(\d+)["']?\s*\)`)
+	gradleCompatibility = regexp.MustCompile(`sourceCompatibility\s*=\s*(?:JavaVersion\.VERSION_)?["']?(1[._]\d+|\d+)["']?`)
+	mavenProperty       = regexp.MustCompile(`\$\{([^}]+)\}`)
+)
+
+// buildFileVersion returns the maven or gradle build file in dir and the java version it declares,
+// the build file is empty if there is none
+func buildFileVersion(dir string) (string, string, error) {
+	bf := filepath.Join(dir, pomFile)
+	if data, err := os.ReadFile(bf); err == nil {
+		v, err := pomVersion(bf, data)
+		return bf, v, err
+	}
+	for _, name := range []string{gradleKotlinFile, gradleFile} {
+		bf := filepath.Join(dir, name)
+		data, err := os.ReadFile(bf)
+		if err != nil {
+			continue
+		}
+		return bf, gradleVersion(data), nil
+	}
+	return "", "", nil
+}
+
+type pom struct {
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Build struct {
+		Plugins []struct {
+			ArtifactID    string `xml:"artifactId"`
+			Configuration struct {
+				Toolchains struct {
+					JDK struct {
+						Version string `xml:"version"`
+					} `xml:"jdk"`
+				} `xml:"toolchains"`
+			} `xml:"configuration"`
+		} `xml:"plugins>plugin"`
+	} `xml:"build"`
+}
+
+// pomVersion returns the jdk version of the toolchains plugin, or the maven.compiler.release,
+// maven.compiler.source or java.version property in order
+func pomVersion(name string, data []byte) (string, error) {
+	var p pom
+	if err := xml.Unmarshal(data, &p); err != nil {
+		return "", fmt.Errorf("parse %s failed, %w", name, err)
+	}
+	properties := map[string]string{}
+	for _, e := range p.Properties.Entries {
+		properties[e.XMLName.Local] = strings.TrimSpace(e.Value)
+	}
+	// ${java.version} references another property
+	expand := func(v string) string {
+		return mavenProperty.ReplaceAllStringFunc(v, func(ref string) string {
+			return properties[mavenProperty.FindStringSubmatch(ref)[1]]
+		})
+	}
+	for _, plugin := range p.Build.Plugins {
+		if plugin.ArtifactID != "maven-toolchains-plugin" {
+			continue
+		}
+		if v := expand(strings.TrimSpace(plugin.Configuration.Toolchains.JDK.Version)); v != "" {
+			return mavenRange(v), nil
+		}
+	}
+	for _, key := range []string{"maven.compiler.release", "maven.compiler.source", "java.version"} {
+		if v := expand(properties[key]); v != "" {
+			return majorVersion(v), nil
+		}
+	}
+	return "", nil
+}
+
+// mavenRange converts the version range of maven to the version spec, such as [17,21) to >=17 <21
+func mavenRange(v string) string {
+	if !strings.HasPrefix(v, "[") && !strings.HasPrefix(v, "(") {
+		return majorVersion(v)
+	}
+	if len(v) < 2 {
+		return ""
+	}
+	lower, upper, ok := strings.Cut(v[1:len(v)-1], ",")
+	if !ok {
+		// [17] is exactly 17
+		return majorVersion(lower)
+	}
+	var spec []string
+	if lower = strings.TrimSpace(lower); lower != "" {
+		op := ">="
+		if v[0] == '(' {
+			op = ">"
+		}
+		spec = append(spec, op+majorVersion(lower))
+	}
+	if upper = strings.TrimSpace(upper); upper != "" {
+		op := "<="
+		if v[len(v)-1] == ')' {
+			op = "<"
+		}
+		spec = append(spec, op+majorVersion(upper))
+	}
+	return strings.Join(spec, " ")
+}
+
+// gradleVersion returns the language version of the java toolchain, or the sourceCompatibility
+func gradleVersion(data []byte) string {
+	if m := gradleToolchain.FindSubmatch(data); m != nil {
+		return string(m[1])
+	}
+	if m := gradleCompatibility.FindSubmatch(data); m != nil {
+		return majorVersion(strings.ReplaceAll(string(m[1]), "_", "."))
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package java
+
+import "testing"
+
+func TestPomVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		pom  string
+		want string
+	}{
+		{
+			name: "release",
+			pom:  `<project><properties><maven.compiler.release>17</maven.compiler.release></properties></project>`,
+			want: "17",
+		},
+		{
+			name: "namespaced",
+			pom: `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <modelVersion>4.0.0</modelVersion>
+  <properties>
+    <maven.compiler.source> 1.8 </maven.compiler.source>
+  </properties>
+</project>`,
+			want: "8",
+		},
+		{
+			name: "release before source and java.version",
+			pom: `<project><properties>
+  <java.version>11</java.version>
+  <maven.compiler.source>17</maven.compiler.source>
+  <maven.compiler.release>21</maven.compiler.release>
+</properties></project>`,
+			want: "21",
+		},
+		{
+			name: "property reference",
+			pom: `<project><properties>
+  <java.version>17</java.version>
+  <maven.compiler.release>${java.version}</maven.compiler.release>
+</properties></project>`,
+			want: "17",
+		},
+		{
+			name: "undefined property reference",
+			pom: `<project><properties>
+  <maven.compiler.release>${jdk}</maven.compiler.release>
+  <java.version>11</java.version>
+</properties></project>`,
+			want: "11",
+		},
+		{
+			name: "toolchains plugin",
+			pom: `<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <properties>
+    <jdk.version>[17,21)</jdk.version>
+    <maven.compiler.release>11</maven.compiler.release>
+  </properties>
+  <build><plugins>
+    <plugin><artifactId>maven-compiler-plugin</artifactId></plugin>
+    <plugin>
+      <artifactId>maven-toolchains-plugin</artifactId>
+      <configuration><toolchains><jdk><version>${jdk.version}</version></jdk></toolchains></configuration>
+    </plugin>
+  </plugins></build>
+</project>`,
+			want: ">=17 <21",
+		},
+		{
+			name: "none",
+			pom:  `<project><properties><project.build.sourceEncoding>UTF-8</project.build.sourceEncoding></properties></project>`,
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		got, err := pomVersion(pomFile, []byte(tt.pom))
+		if err != nil {
+			t.Errorf("%s: pomVersion failed: %s", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: pomVersion = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPomVersionInvalid(t *testing.T) {
+	if _, err := pomVersion(pomFile, []byte(`<project><properties>`)); err == nil {
+		t.Errorf("pomVersion of a truncated pom succeeded, want an error")
+	}
+}
+
+func TestMavenRange(t *testing.T) {
+	tests := []struct {
+		v    string
+		want string
+	}{
+		{"17", "17"},
+		{"1.8", "8"},
+		{"[17]", "17"},
+		{"[17,21)", ">=17 <21"},
+		{"[17, 21]", ">=17 <=21"},
+		{"(1.8,11]", ">8 <=11"},
+		{"[17,)", ">=17"},
+		{"(,21)", "<21"},
+		{"[", ""},
+	}
+	for _, tt := range tests {
+		if got := mavenRange(tt.v); got != tt.want {
+			t.Errorf("mavenRange(%q) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestGradleVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		gradle string
+		want   string
+	}{
+		{"toolchain", `java { toolchain { languageVersion = JavaLanguageVersion.of(17) } }`, "17"},
+		{"kotlin toolchain", `java { toolchain { languageVersion.set(JavaLanguageVersion.of("21")) } }`, "21"},
+		{"toolchain before compatibility", "sourceCompatibility = '11'\njava.toolchain.languageVersion = JavaLanguageVersion.of(17)", "17"},
+		{"legacy enum", `sourceCompatibility = JavaVersion.VERSION_1_8`, "8"},
+		{"enum", `java.sourceCompatibility = JavaVersion.VERSION_17`, "17"},
+		{"quoted", `sourceCompatibility = "1.8"`, "8"},
+		{"number", `sourceCompatibility = 11`, "11"},
+		{"none", `plugins { id 'java' }`, ""},
+	}
+	for _, tt := range tests {
+		if got := gradleVersion([]byte(tt.gradle)); got != tt.want {
+			t.Errorf("%s: gradleVersion = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,25 +1,64 @@
 package java
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/modern-devops/xvm/mirrors"
 	"github.com/modern-devops/xvm/sdks"
 	"github.com/modern-devops/xvm/tools"
+	"github.com/modern-devops/xvm/tools/manifest"
+
+	"github.com/rs/zerolog/log"
 )
 
 const (
 	java = "java"
 )
 
-const javaVersionFile = ".javaversion"
+const (
+	javaVersionFile      = ".javaversion"
+	javaDistributionFile = ".javadistribution"
+)
+
+// versionFiles in order of precedence, .java-version is the one of jenv and .sdkmanrc is the one of sdkman
+var versionFiles = []*tools.VersionFile{
+	manifest.VersionFile(java),
+	{Name: javaVersionFile},
+	{
+		Name: ".java-version",
+		Parse: func(data []byte) (string, error) {
+			v, err := tools.ParseVersionLine(data)
+			return majorVersion(v), err
+		},
+	},
+	{Name: ".sdkmanrc", Parse: parseSdkmanrc},
+	{
+		Name: tools.ToolVersionsFile,
+		Parse: func(data []byte) (string, error) {
+			v, err := tools.ToolVersions(java).Parse(data)
+			return asdfVersion(v), err
+		},
+	},
+}
+
+// macJavaHome is where the jdk bundles of macOS keep the actual java home
+var macJavaHome = filepath.Join("Contents", "Home")
 
 var javaTools = []string{java, "javac", "javadoc", "jsk", "jstack", "jar", "jlink", "jpackage"}
 
 type jvm struct {
 	home string
+	mu   sync.Mutex
+	// distributions caches the distribution detected for each working directory
+	distributions map[string]string
+	// unknown keeps the unknown distributions reported already
+	unknown map[string]bool
 }
 
 func Jvm(home string) *jvm {
@@ -35,31 +74,202 @@ func (j *jvm) Info() *sdks.SdkInfo {
 		})
 	}
 	return &sdks.SdkInfo{
-		Name:   java,
-		Tools:  sts,
-		Mirror: mirrors.Java(),
+		Name:        java,
+		Tools:       sts,
+		Mirror:      mirrors.Java(j.Distribution()),
+		VersionFile: javaVersionFile,
 		WithEnvs: func(wp string) []string {
-			return []string{"JAVA_HOME" + "=" + wp}
+			return []string{"JAVA_HOME" + "=" + javaHome(wp)}
 		},
+		PostInstall: linkMacJavaHome,
+		Qualify:     j.qualify,
+		QualifiedMirror: func(distribution string) mirrors.Mirror {
+			return mirrors.Java(distribution)
+		},
+		// zulu was the only distribution before the versions were qualified
+		LegacyQualifier: mirrors.DefaultJavaDistribution(),
+	}
+}
+
+// qualify splits the distribution off the version spec, such as temurin-21.0.2,
+// the distribution of the working directory is used if the spec has none
+func (j *jvm) qualify(spec string) (string, string) {
+	if d, v, ok := splitDistribution(spec); ok {
+		return d, v
+	}
+	return j.Distribution(), spec
+}
+
+// splitDistribution splits the distribution off the version spec, ok is false if the spec has none
+func splitDistribution(spec string) (string, string, bool) {
+	for _, d := range mirrors.JavaDistributions() {
+		if spec == d {
+			return d, "", true
+		}
+		if v, ok := strings.CutPrefix(spec, d+"-"); ok {
+			return d, v, true
+		}
 	}
+	return "", spec, false
 }
 
-// Version try to detect the go version
-func (j *jvm) Version() (string, error) {
-	// 1. detect from .javaversion
-	vfs, err := tools.DetectVersionFiles(javaVersionFile)
+// Distribution returns the java distribution used by the current directory
+func (j *jvm) Distribution() string {
+	wd, err := os.Getwd()
 	if err != nil {
-		return "", err
+		wd = ""
 	}
-	for _, vf := range vfs {
-		if _, err := os.Stat(vf); err != nil {
-			continue
+	return j.distributionOf(wd)
+}
+
+// distributionOf returns the java distribution used by the working directory wd, which is detected once per directory,
+// XVM_JAVA_DISTRIBUTION takes precedence over the .xvm.toml and .javadistribution files,
+// the default one is used if none is defined or it is unknown
+func (j *jvm) distributionOf(wd string) string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if d, ok := j.distributions[wd]; ok {
+		return d
+	}
+	d := detectDistribution(wd)
+	if d != "" && !slices.Contains(mirrors.JavaDistributions(), d) {
+		if !j.unknown[d] {
+			log.Error().Msgf("unknown distribution: %s, uses %s instead.", d, mirrors.DefaultJavaDistribution())
+			if j.unknown == nil {
+				j.unknown = map[string]bool{}
+			}
+			j.unknown[d] = true
 		}
-		data, err := os.ReadFile(vf)
+		d = ""
+	}
+	if d == "" {
+		d = mirrors.DefaultJavaDistribution()
+	}
+	if j.distributions == nil {
+		j.distributions = map[string]string{}
+	}
+	j.distributions[wd] = d
+	return d
+}
+
+func detectDistribution(wd string) string {
+	if d := os.Getenv("XVM_JAVA_DISTRIBUTION"); d != "" {
+		return d
+	}
+	if wd == "" {
+		return ""
+	}
+	d, err := tools.DetectVersion(wd, manifest.DistributionFile(), &tools.VersionFile{Name: javaDistributionFile})
+	if err != nil {
+		return ""
+	}
+	return d.Version
+}
+
+// Version try to detect the java version, which is qualified by the distribution of wd if it has none,
+// so that the versions of other projects are not qualified by the distribution of the current directory
+func (j *jvm) Version(wd string) (*tools.Version, error) {
+	v, err := j.detectVersion(wd)
+	if err != nil || v.Version == "" {
+		return v, err
+	}
+	if _, _, ok := splitDistribution(v.Version); !ok {
+		v.Version = j.distributionOf(wd) + "-" + v.Version
+	}
+	return v, nil
+}
+
+func (j *jvm) detectVersion(wd string) (*tools.Version, error) {
+	// 1. detect from .xvm.toml, .javaversion, .java-version, .sdkmanrc and .tool-versions
+	v, err := tools.DetectVersion(wd, versionFiles...)
+	if err != nil || v.Version != "" {
+		return v, err
+	}
+	// 2. detect from pom.xml, build.gradle.kts and build.gradle
+	for _, dir := range tools.ParentDirs(wd) {
+		bf, bv, err := buildFileVersion(dir)
 		if err != nil {
-			return "", err
+			return v, err
+		}
+		if bf == "" {
+			v.Miss(filepath.Join(dir, pomFile))
+			continue
+		}
+		if v.Check(bf, bv) {
+			return v, nil
+		}
+	}
+	return v, nil
+}
+
+// asdfVersion converts the java version of asdf to the one of xvm, such as adoptopenjdk-17.0.8+7 to temurin-17.0.8
+func asdfVersion(v string) string {
+	for prefix, distribution := range map[string]string{"adoptopenjdk-": "temurin-", "graalvm-community-": "graalvm-"} {
+		if rest, ok := strings.CutPrefix(v, prefix); ok {
+			v = distribution + rest
+		}
+	}
+	v, _, _ = strings.Cut(v, "+")
+	return v
+}
+
+// sdkmanVendors maps the vendors of the sdkman candidates to the xvm distributions
+var sdkmanVendors = map[string]string{
+	"tem":     "temurin",
+	"zulu":    "zulu",
+	"amzn":    "corretto",
+	"librca":  "liberica",
+	"graalce": "graalvm",
+}
+
+// parseSdkmanrc converts the java candidate of sdkman to the version of xvm, such as java=21.0.2-tem to temurin-21.0.2
+func parseSdkmanrc(data []byte) (string, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || strings.TrimSpace(key) != java {
+			continue
+		}
+		version, vendor, _ := strings.Cut(strings.TrimSpace(value), "-")
+		// javafx bundled builds, such as 21.0.2.fx-librca
+		version = strings.TrimSuffix(version, ".fx")
+		if d, ok := sdkmanVendors[vendor]; ok {
+			return d + "-" + version, nil
 		}
-		return strings.TrimSpace(string(data)), nil
+		return version, nil
 	}
 	return "", nil
 }
+
+// majorVersion converts the legacy versions, such as 1.8 and 1.8.0_392, to the major version 8
+func majorVersion(v string) string {
+	if rest, ok := strings.CutPrefix(v, "1."); ok && rest != "" && rest[0] >= '1' && rest[0] <= '8' {
+		major, _, _ := strings.Cut(strings.ReplaceAll(rest, "_", "."), ".")
+		return major
+	}
+	return v
+}
+
+// javaHome returns the java home of the installed jdk, which is nested in the bundle layout of macOS
+func javaHome(wp string) string {
+	if home := filepath.Join(wp, macJavaHome); runtime.GOOS == "darwin" && isDir(home) {
+		return home
+	}
+	return wp
+}
+
+// linkMacJavaHome links the bin of the macOS bundle layout, so the tools are found in the same place
+func linkMacJavaHome(wp string) error {
+	home := javaHome(wp)
+	if home == wp {
+		return nil
+	}
+	if _, err := os.Lstat(filepath.Join(wp, "bin")); !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return os.Symlink(filepath.Join(macJavaHome, "bin"), filepath.Join(wp, "bin"))
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
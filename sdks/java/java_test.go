@@ -0,0 +1,101 @@
+package java
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modern-devops/xvm/mirrors"
+)
+
+func TestParseSdkmanrc(t *testing.T) {
+	tests := []struct {
+		sdkmanrc string
+		want     string
+	}{
+		{"java=21.0.2-tem", "temurin-21.0.2"},
+		{"java=21.0.2.fx-librca", "liberica-21.0.2"},
+		{"java=17.0.9-amzn", "corretto-17.0.9"},
+		{"java=21.0.2-graalce", "graalvm-21.0.2"},
+		{"java=11.0.21-zulu", "zulu-11.0.21"},
+		{"java=17.0.9-ms", "17.0.9"},
+		{"java=17.0.9", "17.0.9"},
+		{"# Enable auto-env through the sdkman_auto_env config\r\ngradle=8.5\r\n java = 21.0.2-tem \r\n", "temurin-21.0.2"},
+		{"# java=21.0.2-tem\nmaven=3.9.6", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := parseSdkmanrc([]byte(tt.sdkmanrc))
+		if err != nil {
+			t.Errorf("parseSdkmanrc(%q) failed: %s", tt.sdkmanrc, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSdkmanrc(%q) = %q, want %q", tt.sdkmanrc, got, tt.want)
+		}
+	}
+}
+
+func TestMajorVersion(t *testing.T) {
+	tests := []struct {
+		v    string
+		want string
+	}{
+		{"1.8", "8"},
+		{"1.8.0_392", "8"},
+		{"1.7.0", "7"},
+		{"8", "8"},
+		{"11.0.2", "11.0.2"},
+		{"17", "17"},
+		{"21.0.2", "21.0.2"},
+		{"1.", "1."},
+		{"temurin-17", "temurin-17"},
+	}
+	for _, tt := range tests {
+		if got := majorVersion(tt.v); got != tt.want {
+			t.Errorf("majorVersion(%q) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestVersionQualifiedByDistributionOfWd(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HOME", root)
+	t.Setenv("XVM_CEILING_DIRECTORIES", root)
+	t.Setenv("XVM_JAVA_DISTRIBUTION", "")
+	files := map[string]string{
+		"temurin/.javadistribution": "temurin",
+		"temurin/.javaversion":      "21",
+		"default/.javaversion":      "17",
+		"qualified/.javaversion":    "corretto-17.0.9",
+	}
+	for name, content := range files {
+		file := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	j := Jvm(root)
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"temurin", "temurin-21"},
+		{"default", mirrors.DefaultJavaDistribution() + "-17"},
+		{"qualified", "corretto-17.0.9"},
+		{"temurin", "temurin-21"},
+	}
+	for _, tt := range tests {
+		v, err := j.Version(filepath.Join(root, tt.dir))
+		if err != nil {
+			t.Errorf("Version(%s) failed: %s", tt.dir, err)
+			continue
+		}
+		if v.Version != tt.want {
+			t.Errorf("Version(%s) = %q, want %q", tt.dir, v.Version, tt.want)
+		}
+	}
+}
@@ -1,13 +1,17 @@
 package node
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/modern-devops/xvm/mirrors"
 	"github.com/modern-devops/xvm/sdks"
 	"github.com/modern-devops/xvm/tools"
+	"github.com/modern-devops/xvm/tools/manifest"
+
+	"github.com/rs/zerolog/log"
 )
 
 const (
@@ -15,7 +19,32 @@ const (
 	npm  = "npm"
 )
 
-const bin = "bin"
+const (
+	bin             = "bin"
+	packageJSONFile = "package.json"
+	nodeVersionFile = ".nodeversion"
+)
+
+// versionFiles in order of precedence, .nvmrc is the one of nvm and .node-version is the one of nodenv, fnm and others
+var versionFiles = []*tools.VersionFile{
+	manifest.VersionFile(node),
+	{Name: nodeVersionFile},
+	{Name: ".nvmrc", Parse: parseNvmrc},
+	{Name: ".node-version"},
+	{
+		Name: packageJSONFile,
+		Parse: func(data []byte) (string, error) {
+			// a malformed package.json defines no version, like the one without volta
+			p, err := parsePackageJSON(packageJSONFile, data)
+			if err != nil {
+				log.Debug().Msgf("Skip %s", err)
+				return "", nil
+			}
+			return p.Volta.Node, nil
+		},
+	},
+	tools.ToolVersions(node),
+}
 
 type nvm struct {
 	home string
@@ -39,8 +68,9 @@ func (n *nvm) Info() *sdks.SdkInfo {
 				Path: npmCommandFile(),
 			},
 		},
-		BinPaths: []string{npmPackagesBinPath(prefix)},
-		Mirror:   mirrors.Node(),
+		BinPaths:    []string{npmPackagesBinPath(prefix)},
+		Mirror:      mirrors.Node(),
+		VersionFile: nodeVersionFile,
 		WithEnvs: func(wp string) []string {
 			envPrefix := "PREFIX"
 			if os.Getenv(envPrefix) == "" {
@@ -48,35 +78,87 @@ func (n *nvm) Info() *sdks.SdkInfo {
 			}
 			return nil
 		},
-		PostInstall: func(wp string) error {
+		PreRun: func(wp string) error {
 			if !tools.IsWindows() {
 				return nil
 			}
-			if err := os.MkdirAll(prefix, os.ModeDir); err != nil {
-				return err
-			}
-			return os.Symlink(filepath.Join(wp, nodeCommandFile()), filepath.Join(prefix, nodeCommandFile()))
+			return linkNode(prefix, wp)
 		},
 	}
 }
 
+// linkNode points the node of the npm prefix to the running version,
+// it is done before running instead of after installing since the install is staged elsewhere
+func linkNode(prefix, wp string) error {
+	target := filepath.Join(wp, nodeCommandFile())
+	link := filepath.Join(prefix, nodeCommandFile())
+	if current, err := os.Readlink(link); err == nil && current == target {
+		return nil
+	}
+	if err := os.MkdirAll(prefix, 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(target, link)
+}
+
 // Version try to detect the node version
-func (n *nvm) Version() (string, error) {
-	vfs, err := tools.DetectVersionFiles(".nodeversion")
-	if err != nil {
-		return "", err
+func (n *nvm) Version(wd string) (*tools.Version, error) {
+	// 1. detect from .xvm.toml, .nodeversion, .nvmrc, .node-version, package.json#volta.node and .tool-versions
+	v, err := tools.DetectVersion(wd, versionFiles...)
+	if err != nil || v.Version != "" {
+		return v, err
 	}
-	for _, vf := range vfs {
-		if _, err := os.Stat(vf); err != nil {
+	// 2. detect from package.json#engines.node
+	for _, pj := range tools.DetectFiles(wd, packageJSONFile) {
+		data, err := os.ReadFile(pj)
+		if err != nil {
+			v.Miss(pj + "#engines.node")
 			continue
 		}
-		data, err := os.ReadFile(vf)
+		p, err := parsePackageJSON(pj, data)
 		if err != nil {
-			return "", err
+			log.Debug().Msgf("Skip %s", err)
+			v.Check(pj+"#engines.node", "")
+			continue
 		}
-		return strings.TrimSpace(string(data)), nil
+		if v.Check(pj+"#engines.node", p.Engines.Node) {
+			return v, nil
+		}
+	}
+	return v, nil
+}
+
+type packageJSON struct {
+	Volta struct {
+		Node string `json:"node"`
+	} `json:"volta"`
+	Engines struct {
+		Node string `json:"node"`
+	} `json:"engines"`
+}
+
+func parsePackageJSON(name string, data []byte) (*packageJSON, error) {
+	var p packageJSON
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse %s failed, %w", name, err)
+	}
+	return &p, nil
+}
+
+// parseNvmrc converts the aliases of nvm to the ones of xvm, lts/* and lts/<codename> are the same
+func parseNvmrc(data []byte) (string, error) {
+	v, err := tools.ParseVersionLine(data)
+	if err != nil {
+		return "", err
+	}
+	switch v {
+	case "node", "stable":
+		return "latest", nil
 	}
-	return "", nil
+	return v, nil
 }
 
 func npmPackagesBinPath(npmPackages string) string {
@@ -0,0 +1,49 @@
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVersionSkipsMalformedPackageJSON(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HOME", root)
+	t.Setenv("XVM_CEILING_DIRECTORIES", filepath.Dir(root))
+	files := map[string]string{
+		"package.json":              `{"name": "monorepo",`,
+		"engines/package.json":      `{"engines": {"node": ">=18"}}`,
+		"volta/package.json":        `{"volta": {"node": "20.11.1"}}`,
+		"broken/package.json":       `{"volta": `,
+		"broken/inner/package.json": `not json`,
+	}
+	for name, content := range files {
+		file := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"engines", ">=18"},
+		{"volta", "20.11.1"},
+		{"broken/inner", ""},
+		{"", ""},
+	}
+	n := Nvm(root)
+	for _, tt := range tests {
+		v, err := n.Version(filepath.Join(root, tt.dir))
+		if err != nil {
+			t.Errorf("Version(%s) failed: %s", tt.dir, err)
+			continue
+		}
+		if v.Version != tt.want {
+			t.Errorf("Version(%s) = %q, want %q", tt.dir, v.Version, tt.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/modern-devops/xvm/mirrors"
+	"github.com/modern-devops/xvm/sdks"
+	"github.com/modern-devops/xvm/tools"
+	"github.com/modern-devops/xvm/tools/manifest"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Definition is a sdk declared by a config file, paths and env values may contain {home} for the user's home,
+// env values {root} for the root path of the installed version, tool paths {exe} for .exe on windows
+type Definition struct {
+	Name  string `yaml:"name"`
+	Tools []struct {
+		Name string `yaml:"name"`
+		Path string `yaml:"path"`
+	} `yaml:"tools"`
+	BinPaths     []string          `yaml:"binPaths"`
+	Env          map[string]string `yaml:"env"`
+	VersionFiles []string          `yaml:"versionFiles"`
+	// StripComponents is the number of leading path components stripped from the archive, 1 by default
+	StripComponents *int `yaml:"stripComponents"`
+
+	mirrors.PluginOptions `yaml:",inline"`
+}
+
+type plugin struct {
+	home   string
+	def    *Definition
+	mirror mirrors.Mirror
+}
+
+// Files returns the *.yaml and *.yml config files of the plugin sdks in the directories
+func Files(dirs ...string) []string {
+	var files []string
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.y*ml"))
+		if err != nil {
+			continue
+		}
+		for _, file := range matches {
+			if ext := filepath.Ext(file); ext == ".yaml" || ext == ".yml" {
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// LoadFiles loads the sdks defined by the config files, a sdk is skipped with a warning if it is invalid or its name is reserved
+func LoadFiles(home string, reserved []string, files ...string) []sdks.Sdk {
+	var loaded []sdks.Sdk
+	for _, file := range files {
+		p, err := load(home, file)
+		if err != nil {
+			log.Warn().Msgf("Skip the plugin %s: %s", file, err)
+			continue
+		}
+		name := p.def.Name
+		if slices.Contains(reserved, name) {
+			log.Warn().Msgf("Skip the plugin %s: the sdk %s is already defined", file, name)
+			continue
+		}
+		reserved = append(reserved, name)
+		loaded = append(loaded, p)
+	}
+	return loaded
+}
+
+func load(home, file string) (*plugin, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var def Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, err
+	}
+	if def.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	// the name is a part of paths and of XVM_{NAME}_VERSION
+	if !tools.ValidEnvName(def.Name) {
+		return nil, fmt.Errorf("invalid name: %s, which allows letters, digits and underscores only", def.Name)
+	}
+	for k := range def.Env {
+		if !tools.ValidEnvName(k) {
+			return nil, fmt.Errorf("invalid env name: %s", k)
+		}
+	}
+	if len(def.Tools) == 0 {
+		return nil, fmt.Errorf("at least one tool is required")
+	}
+	m, err := mirrors.Plugin(&def.PluginOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin{home: home, def: &def, mirror: m}, nil
+}
+
+func (p *plugin) Info() *sdks.SdkInfo {
+	sts := make([]sdks.Tool, 0, len(p.def.Tools))
+	for _, tool := range p.def.Tools {
+		sts = append(sts, sdks.Tool{
+			Name: tool.Name,
+			Path: filepath.FromSlash(p.expand(tool.Path, "")),
+		})
+	}
+	binPaths := make([]string, 0, len(p.def.BinPaths))
+	for _, bp := range p.def.BinPaths {
+		binPaths = append(binPaths, filepath.FromSlash(p.expand(bp, "")))
+	}
+	info := &sdks.SdkInfo{
+		Name:     p.def.Name,
+		Tools:    sts,
+		BinPaths: binPaths,
+		Mirror:   p.mirror,
+		WithEnvs: func(wp string) []string {
+			envs := make([]string, 0, len(p.def.Env))
+			for k, v := range p.def.Env {
+				envs = append(envs, k+"="+p.expand(v, wp))
+			}
+			return envs
+		},
+	}
+	if len(p.def.VersionFiles) > 0 {
+		info.VersionFile = p.def.VersionFiles[0]
+	}
+	if sc := p.def.StripComponents; sc != nil {
+		info.Extract = func(filename, wp string) error {
+			return tools.UnarchiveStrip(filename, wp, *sc)
+		}
+	}
+	return info
+}
+
+// Version try to detect the version from the version files of the plugin
+func (p *plugin) Version(wd string) (*tools.Version, error) {
+	vfs := make([]*tools.VersionFile, 0, len(p.def.VersionFiles)+2)
+	vfs = append(vfs, manifest.VersionFile(p.def.Name))
+	for _, name := range p.def.VersionFiles {
+		vfs = append(vfs, &tools.VersionFile{Name: name})
+	}
+	vfs = append(vfs, tools.ToolVersions(p.def.Name))
+	return tools.DetectVersion(wd, vfs...)
+}
+
+func (p *plugin) expand(s, root string) string {
+	exe := ""
+	if tools.IsWindows() {
+		exe = ".exe"
+	}
+	return strings.NewReplacer("{home}", p.home, "{root}", root, "{exe}", exe).Replace(s)
+}
@@ -0,0 +1,135 @@
+package python
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modern-devops/xvm/mirrors"
+	"github.com/modern-devops/xvm/sdks"
+	"github.com/modern-devops/xvm/tools"
+	"github.com/modern-devops/xvm/tools/constraint"
+	"github.com/modern-devops/xvm/tools/manifest"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	python  = "python"
+	python3 = "python3"
+	pip     = "pip"
+	bin     = "bin"
+)
+
+const (
+	pyprojectFile     = "pyproject.toml"
+	pythonVersionFile = ".python-version"
+)
+
+// versionFiles in order of precedence, .python-version is the one of pyenv, which allows one version per line
+var versionFiles = []*tools.VersionFile{
+	manifest.VersionFile(python),
+	{Name: pythonVersionFile, Parse: parsePythonVersion},
+	{Name: ".pythonversion"},
+	tools.ToolVersions(python),
+}
+
+type pvm struct {
+	home string
+}
+
+func Pvm(home string) *pvm {
+	return &pvm{home: home}
+}
+
+func (p *pvm) Info() *sdks.SdkInfo {
+	return &sdks.SdkInfo{
+		Name: python,
+		Tools: []sdks.Tool{
+			{
+				Name: python,
+				Path: pythonCommandFile(),
+			},
+			{
+				Name: python3,
+				Path: pythonCommandFile(),
+			},
+			{
+				Name: pip,
+				Path: pipCommandFile(),
+			},
+		},
+		Mirror:      mirrors.Python(),
+		VersionFile: pythonVersionFile,
+		WithEnvs: func(wp string) []string {
+			// isolate the user site-packages of every version, the stash path ends with the version
+			userBase := filepath.Join(p.home, python, filepath.Base(wp))
+			return []string{"PYTHONUSERBASE" + "=" + userBase}
+		},
+	}
+}
+
+// Version try to detect the python version
+func (p *pvm) Version(wd string) (*tools.Version, error) {
+	// 1. detect from .xvm.toml, .python-version, .pythonversion and .tool-versions
+	v, err := tools.DetectVersion(wd, versionFiles...)
+	if err != nil || v.Version != "" {
+		return v, err
+	}
+	// 2. detect from pyproject.toml
+	for _, pp := range tools.DetectFiles(wd, pyprojectFile) {
+		if _, err := os.Stat(pp); err != nil {
+			v.Miss(pp + "#requires-python")
+			continue
+		}
+		rp, err := getRequiresPython(pp)
+		if err != nil {
+			return v, err
+		}
+		if v.Check(pp+"#requires-python", rp) {
+			return v, nil
+		}
+	}
+	return v, nil
+}
+
+// parsePythonVersion returns the first version of .python-version, the other lines of pyenv,
+// such as system and the names of virtualenvs, are ignored
+func parsePythonVersion(data []byte) (string, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := constraint.Parse(line); err == nil {
+			return line, nil
+		}
+	}
+	return "", nil
+}
+
+func getRequiresPython(pp string) (string, error) {
+	var pyproject struct {
+		Project struct {
+			RequiresPython string `toml:"requires-python"`
+		} `toml:"project"`
+	}
+	if _, err := toml.DecodeFile(pp, &pyproject); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(pyproject.Project.RequiresPython), nil
+}
+
+func pythonCommandFile() string {
+	if tools.IsWindows() {
+		return tools.CommandFile(python)
+	}
+	return filepath.Join(bin, python3)
+}
+
+func pipCommandFile() string {
+	if tools.IsWindows() {
+		return filepath.Join("Scripts", tools.CommandFile(pip))
+	}
+	return filepath.Join(bin, "pip3")
+}
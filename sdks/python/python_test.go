@@ -0,0 +1,29 @@
+package python
+
+import "testing"
+
+func TestParsePythonVersion(t *testing.T) {
+	tests := []struct {
+		pythonVersion string
+		want          string
+	}{
+		{"3.11.4", "3.11.4"},
+		{"3.12\n", "3.12"},
+		{"system", ""},
+		{"my-venv\nsystem\n", ""},
+		{"my-venv\n3.11.4\n3.10", "3.11.4"},
+		{"# managed by pyenv\r\n\r\nsystem\r\n3.10.13\r\n", "3.10.13"},
+		{"3.11.4/envs/my-venv\n3.11.4", "3.11.4"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := parsePythonVersion([]byte(tt.pythonVersion))
+		if err != nil {
+			t.Errorf("parsePythonVersion(%q) failed: %s", tt.pythonVersion, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parsePythonVersion(%q) = %q, want %q", tt.pythonVersion, got, tt.want)
+		}
+	}
+}
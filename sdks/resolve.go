@@ -0,0 +1,227 @@
+package sdks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/modern-devops/xvm/mirrors"
+	"github.com/modern-devops/xvm/tools/constraint"
+)
+
+// ResolveVersion resolves the version spec, such as 1.21 or ^18.2, to a complete version,
+// an installed version satisfying the spec is preferred to the highest matching one of the mirror,
+// whose description is returned as well, the latest version is resolved if the spec is empty,
+// the version is qualified as <qualifier>-<version> if the sdk is, such as temurin-21.0.2 of java
+func (i *UserIsolatedInstaller) ResolveVersion(sdk Sdk, spec string) (string, *mirrors.VersionDesc, error) {
+	return i.resolveVersion(sdk, spec, true)
+}
+
+// ResolveMirrorVersion resolves the version spec to the highest matching version of the mirror,
+// regardless of the installed versions
+func (i *UserIsolatedInstaller) ResolveMirrorVersion(sdk Sdk, spec string) (string, *mirrors.VersionDesc, error) {
+	return i.resolveVersion(sdk, spec, false)
+}
+
+func (i *UserIsolatedInstaller) resolveVersion(sdk Sdk, spec string, preferInstalled bool) (string, *mirrors.VersionDesc, error) {
+	qualifier, spec := Qualify(sdk, spec)
+	mirror := MirrorOf(sdk, qualifier)
+	if spec == "" {
+		vd, err := latestVersion(mirror)
+		if err == nil {
+			return Qualified(qualifier, strings.TrimPrefix(vd.Version, "v")), vd, nil
+		}
+		// the latest installed version is the best guess without the index
+		if v := highestVersion(i.installedVersions(sdk, qualifier), nil); v != "" && errors.Is(err, mirrors.ErrOffline) {
+			return Qualified(qualifier, v), nil, nil
+		}
+		return "", nil, err
+	}
+	if isAlias(spec) {
+		vd, err := resolveAlias(sdk, mirror, spec)
+		if err != nil {
+			return "", nil, err
+		}
+		return Qualified(qualifier, strings.TrimPrefix(vd.Version, "v")), vd, nil
+	}
+	c, err := constraint.Parse(spec)
+	if err != nil {
+		return "", nil, err
+	}
+	if v := i.installedVersion(sdk, qualifier, c); v != "" && preferInstalled {
+		return Qualified(qualifier, v), nil, nil
+	}
+	versions, err := mirror.Versions()
+	if err != nil {
+		return "", nil, err
+	}
+	vd := highestVersionDesc(versions, c)
+	if vd == nil {
+		return "", nil, fmt.Errorf("no version of %s matches %s", sdk.Info().Name, Qualified(qualifier, spec))
+	}
+	return Qualified(qualifier, strings.TrimPrefix(vd.Version, "v")), vd, nil
+}
+
+// Qualify splits the version spec into the qualifier and the bare spec by the Qualify hook of the sdk,
+// the qualifier is empty if the sdk is not qualified
+func Qualify(sdk Sdk, spec string) (string, string) {
+	qualify := sdk.Info().Qualify
+	if qualify == nil {
+		return "", spec
+	}
+	qualifier, spec := qualify(spec)
+	return qualifier, strings.TrimPrefix(spec, "v")
+}
+
+// Qualified joins the qualifier and the version as the name of the installation
+func Qualified(qualifier, version string) string {
+	if qualifier == "" {
+		return version
+	}
+	return qualifier + "-" + version
+}
+
+// QualifierOf returns the qualifier of the installed version, which is empty if it is not qualified explicitly
+func QualifierOf(sdk Sdk, version string) string {
+	qualifier, v := Qualify(sdk, version)
+	if Qualified(qualifier, v) != version {
+		return ""
+	}
+	return qualifier
+}
+
+// qualifierOfDir returns the qualifier of the installation directory, the legacy qualifier of the sdk if it is not qualified
+func qualifierOfDir(sdk Sdk, name string) string {
+	if qualifier := QualifierOf(sdk, name); qualifier != "" {
+		return qualifier
+	}
+	return sdk.Info().LegacyQualifier
+}
+
+// MirrorOf returns the mirror of the qualifier, the default mirror of the sdk if it is empty
+func MirrorOf(sdk Sdk, qualifier string) mirrors.Mirror {
+	info := sdk.Info()
+	if qualifier == "" || info.QualifiedMirror == nil {
+		return info.Mirror
+	}
+	return info.QualifiedMirror(qualifier)
+}
+
+// CompareVersions compares the installed versions by the qualifier first and then the version,
+// the ones not qualified explicitly come first
+func CompareVersions(sdk Sdk, a, b string) int {
+	aq, bq := QualifierOf(sdk, a), QualifierOf(sdk, b)
+	if r := strings.Compare(aq, bq); r != 0 {
+		return r
+	}
+	return constraint.Compare(strings.TrimPrefix(a, aq+"-"), strings.TrimPrefix(b, bq+"-"))
+}
+
+const ltsPrefix = "lts/"
+
+// isAlias reports whether the spec names a release channel rather than versions:
+// latest and current for the latest release, lts/* for the latest long-term support release
+// and lts/<codename> for the latest release of the codename, such as lts/hydrogen of node
+func isAlias(spec string) bool {
+	return spec == "latest" || spec == "current" || strings.HasPrefix(spec, ltsPrefix)
+}
+
+// resolveAlias resolves the alias from the metadata of the mirror, which is always consulted
+// since the installed versions know nothing about the channels
+func resolveAlias(sdk Sdk, mirror mirrors.Mirror, alias string) (*mirrors.VersionDesc, error) {
+	versions, err := mirror.Versions()
+	if err != nil {
+		return nil, err
+	}
+	codename, lts := strings.CutPrefix(alias, ltsPrefix)
+	var highest *mirrors.VersionDesc
+	for _, vd := range versions {
+		if lts && (vd.LTS == "" || codename != "*" && !strings.EqualFold(vd.LTS, codename)) {
+			continue
+		}
+		if highest == nil || constraint.Compare(vd.Version, highest.Version) > 0 {
+			highest = vd
+		}
+	}
+	if highest == nil {
+		return nil, fmt.Errorf("no version of %s matches %s", sdk.Info().Name, alias)
+	}
+	return highest, nil
+}
+
+// InstalledVersion returns the installed version the spec resolves to, empty if none is installed
+func (i *UserIsolatedInstaller) InstalledVersion(sdk Sdk, spec string) string {
+	qualifier, spec := Qualify(sdk, spec)
+	c, err := constraint.Parse(spec)
+	if err != nil {
+		return ""
+	}
+	if v := i.installedVersion(sdk, qualifier, c); v != "" {
+		return Qualified(qualifier, v)
+	}
+	return ""
+}
+
+func (i *UserIsolatedInstaller) installedVersion(sdk Sdk, qualifier string, c *constraint.Constraint) string {
+	installed := i.installedVersions(sdk, qualifier)
+	if c.IsExact() && slices.Contains(installed, c.String()) {
+		return c.String()
+	}
+	return highestVersion(installed, c)
+}
+
+// installedVersions returns the completely installed versions of the sdk qualified by the qualifier,
+// without the qualifier
+func (i *UserIsolatedInstaller) installedVersions(sdk Sdk, qualifier string) []string {
+	sp := filepath.Join(i.SdkStashPath, sdk.Info().Name)
+	entries, err := os.ReadDir(sp)
+	if err != nil {
+		return nil
+	}
+	var versions []string
+	for _, entry := range entries {
+		if _, err := os.Stat(filepath.Join(sp, entry.Name(), doneFile)); err != nil {
+			continue
+		}
+		if qualifierOfDir(sdk, entry.Name()) != qualifier {
+			continue
+		}
+		_, v := Qualify(sdk, entry.Name())
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// highestVersion returns the highest version satisfying the constraint, or of all if it is nil
+func highestVersion(versions []string, c *constraint.Constraint) string {
+	var highest string
+	for _, v := range versions {
+		if c != nil && !c.Check(v) {
+			continue
+		}
+		if highest == "" || constraint.Compare(v, highest) > 0 {
+			highest = v
+		}
+	}
+	return highest
+}
+
+func highestVersionDesc(versions []*mirrors.VersionDesc, c *constraint.Constraint) *mirrors.VersionDesc {
+	var highest *mirrors.VersionDesc
+	for _, vd := range versions {
+		// an exact version is preferred to the ones starting with it, such as 17.0.8 to 17.0.8.1
+		if c.IsExact() && strings.TrimPrefix(vd.Version, "v") == c.String() {
+			return vd
+		}
+		if !c.Check(vd.Version) {
+			continue
+		}
+		if highest == nil || constraint.Compare(vd.Version, highest.Version) > 0 {
+			highest = vd
+		}
+	}
+	return highest
+}
@@ -0,0 +1,90 @@
+package sdks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modern-devops/xvm/tools"
+)
+
+// qualifiedSdk is qualified like java, the unqualified versions are of zulu
+type qualifiedSdk struct{}
+
+func (qualifiedSdk) Version(wd string) (*tools.Version, error) {
+	return &tools.Version{}, nil
+}
+
+func (qualifiedSdk) Info() *SdkInfo {
+	return &SdkInfo{
+		Name: "java",
+		Qualify: func(spec string) (string, string) {
+			for _, q := range []string{"zulu", "temurin"} {
+				if v, ok := strings.CutPrefix(spec, q+"-"); ok {
+					return q, v
+				}
+			}
+			return "zulu", spec
+		},
+		LegacyQualifier: "zulu",
+	}
+}
+
+func TestLegacyInstallation(t *testing.T) {
+	installer := NewUserIsolatedInstaller(t.TempDir(), nil)
+	sdk := qualifiedSdk{}
+	legacy := filepath.Join(installer.SdkStashPath, "java", "21.0.2")
+	// an older xvm recorded the sdk info only
+	for root, done := range map[string]string{
+		legacy: `{"name":"java"}`,
+		filepath.Join(installer.SdkStashPath, "java", "temurin-17.0.9"): `{"name":"java","version":"temurin-17.0.9"}`,
+	} {
+		if err := os.MkdirAll(root, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, doneFile), []byte(done), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if v := installer.InstalledVersion(sdk, "21"); v != "zulu-21.0.2" {
+		t.Errorf("InstalledVersion(21) = %q, want zulu-21.0.2", v)
+	}
+	if v := installer.InstalledVersion(sdk, "temurin-21"); v != "" {
+		t.Errorf("InstalledVersion(temurin-21) = %q, want none", v)
+	}
+	for _, spec := range []string{"21", "zulu-21.0.2"} {
+		root, err := installer.InstalledRoot(sdk, spec)
+		if err != nil || root != legacy {
+			t.Errorf("InstalledRoot(%s) = %q, %v, want %q", spec, root, err, legacy)
+		}
+		// installed already, nothing is downloaded
+		root, err = installer.InstallVersion(sdk, spec)
+		if err != nil || root != legacy {
+			t.Errorf("InstallVersion(%s) = %q, %v, want %q", spec, root, err, legacy)
+		}
+	}
+
+	installations, err := installer.Installations(sdk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := map[string]string{}
+	for _, in := range installations {
+		versions[in.Version] = in.Qualifier
+	}
+	if len(versions) != 2 || versions["zulu-21.0.2"] != "zulu" || versions["temurin-17.0.9"] != "temurin" {
+		t.Errorf("Installations = %v, want zulu-21.0.2 of zulu and temurin-17.0.9 of temurin", versions)
+	}
+
+	if err := installer.Uninstall(sdk, "zulu-21.0.2"); err != nil {
+		t.Fatalf("Uninstall(zulu-21.0.2) failed: %s", err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("%s is left after the uninstallation: %v", legacy, err)
+	}
+	if v := installer.InstalledVersion(sdk, "21"); v != "" {
+		t.Errorf("InstalledVersion(21) = %q after the uninstallation, want none", v)
+	}
+}
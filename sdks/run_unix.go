@@ -0,0 +1,45 @@
+//go:build !windows
+
+package sdks
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// forwardedSignals are the signals the child process is sent when xvm receives them
+var forwardedSignals = []os.Signal{unix.SIGINT, unix.SIGTERM, unix.SIGHUP, unix.SIGQUIT, unix.SIGUSR1, unix.SIGUSR2}
+
+// canExec reports whether xvm can be replaced by the tool, which is disabled by XVM_NO_EXEC=true
+func canExec() bool {
+	return strings.ToLower(os.Getenv("XVM_NO_EXEC")) != "true"
+}
+
+// execTool replaces the xvm process by the tool, it only returns on failure
+func execTool(path string, args, env []string) error {
+	if err := unix.Exec(path, append([]string{path}, args...), env); err != nil {
+		return fmt.Errorf("Failed to exec: %s, %w", path, err)
+	}
+	return nil
+}
+
+// propagateSignal kills xvm by the signal that killed the child, so that the caller sees the same termination,
+// it only returns if the child exited normally
+func propagateSignal(state *os.ProcessState) {
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return
+	}
+	sig := ws.Signal()
+	signal.Reset(sig)
+	_ = unix.Kill(os.Getpid(), sig)
+	// the signal is delivered asynchronously, exit as a shell does if it is not
+	time.Sleep(time.Second)
+	os.Exit(128 + int(sig))
+}
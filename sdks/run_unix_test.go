@@ -0,0 +1,66 @@
+//go:build !windows
+
+package sdks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestRunChildForwardsSignals(t *testing.T) {
+	dir := t.TempDir()
+	ready, got := filepath.Join(dir, "ready"), filepath.Join(dir, "got")
+	// the child exits normally rather than being killed, which propagateSignal would do to the test as well,
+	// by 0 once it receives the signal, or by 1 if it does not in 10 seconds
+	script := `trap 'echo "$1" > "` + got + `"; exit 0' INT TERM
+touch "` + ready + `"
+i=0
+while [ $i -lt 100 ]; do sleep 0.1; i=$((i+1)); done
+exit 1`
+	for _, c := range []struct {
+		name string
+		sig  unix.Signal
+	}{
+		// the interrupt of the terminal is forwarded as well, though it is usually sent to the child already
+		{"INT", unix.SIGINT},
+		{"TERM", unix.SIGTERM},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_ = os.Remove(ready)
+			_ = os.Remove(got)
+			done := make(chan error, 1)
+			go func() {
+				done <- runChild(context.Background(), "/bin/sh", []string{"-c", script, "sh", c.name}, os.Environ())
+			}()
+			for {
+				if _, err := os.Stat(ready); err == nil {
+					break
+				}
+				select {
+				case err := <-done:
+					t.Fatalf("the child exited before the signal: %v", err)
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+			// the child has its own pid, so only xvm receives the signal
+			if err := unix.Kill(os.Getpid(), c.sig); err != nil {
+				t.Fatal(err)
+			}
+			if err := <-done; err != nil {
+				t.Fatalf("runChild failed: %v", err)
+			}
+			data, err := os.ReadFile(got)
+			if err != nil {
+				t.Fatalf("the child did not receive %s: %v", c.name, err)
+			}
+			if string(data) != c.name+"\n" {
+				t.Errorf("the child received %q, want %s", data, c.name)
+			}
+		})
+	}
+}
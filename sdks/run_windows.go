@@ -0,0 +1,23 @@
+//go:build windows
+
+package sdks
+
+import (
+	"errors"
+	"os"
+)
+
+// forwardedSignals are the signals the child process is sent when xvm receives them
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+// canExec reports whether xvm can be replaced by the tool, which is impossible on windows
+func canExec() bool {
+	return false
+}
+
+func execTool(path string, args, env []string) error {
+	return errors.New("exec is not supported on windows")
+}
+
+// propagateSignal does nothing, the exit code of the child is all that windows has
+func propagateSignal(state *os.ProcessState) {}
@@ -2,21 +2,23 @@ package sdks
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"slices"
 	"strings"
 
 	"github.com/modern-devops/xvm/mirrors"
 	"github.com/modern-devops/xvm/tools"
+	"github.com/modern-devops/xvm/tools/constraint"
+	"github.com/modern-devops/xvm/tools/filelock"
 	"github.com/modern-devops/xvm/tools/linker"
+	"github.com/modern-devops/xvm/tools/manifest"
 
 	"github.com/rs/zerolog/log"
-	"golang.org/x/mod/semver"
 )
 
 type UserIsolatedInstaller struct {
@@ -25,7 +27,16 @@ type UserIsolatedInstaller struct {
 	BinPath      string
 	DataPath     string
 	ConfigPath   string
+	LockPath     string
+	StagingPath  string
+	CachePath    string
+	PluginPath   string
+	TrustPath    string
 	Sdks         []Sdk
+	// Manifest is the one of the working directory, the env of which is injected into the tools
+	Manifest *manifest.Manifest
+	// Progress is shared by concurrent installations, each download shows its own bar if nil
+	Progress *tools.Progress
 }
 
 func NewUserIsolatedInstaller(home string, sdks []Sdk) *UserIsolatedInstaller {
@@ -36,12 +47,19 @@ func NewUserIsolatedInstaller(home string, sdks []Sdk) *UserIsolatedInstaller {
 		BinPath:      filepath.Join(rp, "bin"),
 		DataPath:     filepath.Join(rp, "data"),
 		ConfigPath:   filepath.Join(rp, "config.ini"),
+		LockPath:     filepath.Join(rp, "locks"),
+		StagingPath:  filepath.Join(rp, "staging"),
+		CachePath:    filepath.Join(rp, "cache"),
+		PluginPath:   filepath.Join(rp, "plugins"),
+		TrustPath:    filepath.Join(rp, "trusted.json"),
 		Sdks:         sdks,
 	}
 }
 
 type Sdk interface {
-	Version() (string, error)
+	// Version returns the version defined by the project at the working directory wd, along with
+	// the sources checked to find it
+	Version(wd string) (*tools.Version, error)
 	Info() *SdkInfo
 }
 
@@ -53,12 +71,27 @@ type SdkInfo struct {
 	WithEnvs    func(wp string) []string `json:"-"`
 	PreRun      func(wp string) error    `json:"-"`
 	PostInstall func(wp string) error    `json:"-"`
+	// Extract extracts the archive into wp, the top-level directory of the archive is stripped if nil
+	Extract func(filename, wp string) error `json:"-"`
+	// VersionFile is the version file of the sdk written by xvm pin, pinning is not supported if empty
+	VersionFile string `json:"versionFile"`
+	// Qualify splits the qualifier, such as the distribution of java, off the version spec, the default qualifier
+	// is returned if the spec has none, the versions of each qualifier are installed side by side as <qualifier>-<version>
+	Qualify func(spec string) (qualifier, version string) `json:"-"`
+	// QualifiedMirror returns the mirror of the qualifier returned by Qualify
+	QualifiedMirror func(qualifier string) mirrors.Mirror `json:"-"`
+	// LegacyQualifier is the qualifier of the versions installed as <version> before the sdk was qualified
+	LegacyQualifier string `json:"-"`
 }
 
 type SdkTool struct {
 	Sdk  Sdk
 	Tool Tool
 	Root string
+	// Defined reports whether the version is defined rather than a fallback to the latest one
+	Defined bool
+	// Manifest declares the environment variables injected into the tools, none are injected if nil
+	Manifest *manifest.Manifest
 }
 
 type Tool struct {
@@ -75,38 +108,169 @@ func (i *UserIsolatedInstaller) Install(name string) (*SdkTool, error) {
 	if err != nil {
 		return nil, err
 	}
-	var vd *mirrors.VersionDesc
-	if version == "" {
-		if vd, err = i.LatestVersion(st.Sdk); err != nil {
-			return nil, err
-		}
-		version = strings.TrimPrefix(vd.Version, "v")
+	if st.Root, err = i.InstallVersion(st.Sdk, version); err != nil {
+		return nil, err
+	}
+	st.Defined = version != ""
+	return st, nil
+}
+
+// Which returns the path of the tool of the version used by the current directory, which must be installed
+func (i *UserIsolatedInstaller) Which(name string) (string, error) {
+	st, err := i.getSdkTool(name)
+	if err != nil {
+		return "", err
 	}
-	st.Root = filepath.Join(i.SdkStashPath, st.Info().Name, version)
-	df := filepath.Join(st.Root, ".done")
+	spec, err := i.GetVersion(st.Sdk)
+	if err != nil {
+		return "", err
+	}
+	root, err := i.InstalledRoot(st.Sdk, spec)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, st.Tool.Path), nil
+}
+
+// InstalledRoot returns the root path of the version the spec resolves to, which must be installed
+func (i *UserIsolatedInstaller) InstalledRoot(sdk Sdk, spec string) (string, error) {
+	version, _, err := i.ResolveVersion(sdk, spec)
+	if err != nil {
+		return "", err
+	}
+	root := i.versionRoot(sdk, version)
+	if _, err := os.Stat(filepath.Join(root, doneFile)); err != nil {
+		return "", fmt.Errorf("%s@v%s is not installed, install it with `xvm install %s@%s`", sdk.Info().Name, version, sdk.Info().Name, version)
+	}
+	return root, nil
+}
+
+// InstallVersion installs the version resolved from the spec unless it is installed, and returns its root path,
+// the latest version is installed if no spec is given
+func (i *UserIsolatedInstaller) InstallVersion(sdk Sdk, spec string) (string, error) {
+	version, vd, err := i.ResolveVersion(sdk, spec)
+	if err != nil {
+		return "", err
+	}
+	root := i.versionRoot(sdk, version)
+	df := filepath.Join(root, doneFile)
+	if _, err := os.Stat(df); err == nil {
+		return root, nil
+	}
+	i.sweepStaging()
+	lock, err := i.lock(sdk.Info().Name, version)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Release()
+	// another process may have finished the installation while waiting for the lock
 	if _, err := os.Stat(df); err == nil {
-		return st, nil
+		return root, nil
 	}
-	log.Info().Msgf("Installing %s@v%s ...", st.Info().Name, version)
-	if err := os.RemoveAll(st.Root); err != nil {
-		return nil, fmt.Errorf("Failed to remove dir: [%s], Please check: %w", st.Root, err)
+	if mirrors.Offline() {
+		return "", fmt.Errorf("%s@v%s is not installed, which cannot be installed in offline mode", sdk.Info().Name, version)
 	}
+	log.Info().Msgf("Installing %s@v%s ...", sdk.Info().Name, version)
 	if vd == nil {
-		if vd, err = st.VersionDesc(version); err != nil {
-			return nil, err
+		if vd, err = versionDesc(sdk, version); err != nil {
+			return "", err
 		}
 	}
-	// TODO(buthim): checksum
-	if err := i.downloadAndExtracting(vd.URL, st.Root); err != nil {
-		return nil, err
+	if err := i.stage(sdk, root, version, vd); err != nil {
+		return "", err
+	}
+	return root, nil
+}
+
+// versionRoot returns the root path of the version, the one installed as <version> before the sdk was qualified
+// is used for <legacy qualifier>-<version> unless that is installed, such as 21.0.2 for zulu-21.0.2 of java
+func (i *UserIsolatedInstaller) versionRoot(sdk Sdk, version string) string {
+	root := filepath.Join(i.SdkStashPath, sdk.Info().Name, version)
+	lq := sdk.Info().LegacyQualifier
+	v, ok := strings.CutPrefix(version, lq+"-")
+	if lq == "" || !ok {
+		return root
+	}
+	if _, err := os.Stat(filepath.Join(root, doneFile)); err == nil {
+		return root
+	}
+	legacy := filepath.Join(i.SdkStashPath, sdk.Info().Name, v)
+	if _, err := os.Stat(filepath.Join(legacy, doneFile)); err == nil {
+		return legacy
+	}
+	return root
+}
+
+// stage installs the version into a staging directory and moves it to the sdk root only on success,
+// so that a failed or interrupted installation never leaves a half-populated sdk root behind
+func (i *UserIsolatedInstaller) stage(sdk Sdk, root, version string, vd *mirrors.VersionDesc) error {
+	vsp := filepath.Join(i.StagingPath, sdk.Info().Name, version)
+	// the lock is held, so everything staged for this version is left by an earlier crash
+	if err := os.RemoveAll(vsp); err != nil {
+		return fmt.Errorf("Failed to remove dir: [%s], Please check: %w", vsp, err)
+	}
+	if err := os.MkdirAll(vsp, 0755); err != nil {
+		return fmt.Errorf("Failed to make dir: [%s], Please check: %w", vsp, err)
+	}
+	defer func() {
+		_ = os.RemoveAll(vsp)
+	}()
+	sp, err := os.MkdirTemp(vsp, "")
+	if err != nil {
+		return fmt.Errorf("Failed to make staging dir: %w", err)
+	}
+	qualifier, _ := Qualify(sdk, version)
+	sha256, err := MirrorOf(sdk, qualifier).Checksum(vd)
+	if err != nil {
+		return fmt.Errorf("Failed to get the checksum of %s: %w", vd.Filename, err)
 	}
-	if pi := st.Info().PostInstall; pi != nil {
+	if err := i.downloadAndExtracting(sdk.Info(), vd.URL, sha256, sp); err != nil {
+		return err
+	}
+	if pi := sdk.Info().PostInstall; pi != nil {
 		log.Info().Msgf("Configuring ...")
-		if err := pi(st.Root); err != nil {
-			return nil, err
+		if err := pi(sp); err != nil {
+			return err
+		}
+	}
+	if err := i.done(sdk.Info(), vd, sha256, version, root, filepath.Join(sp, doneFile)); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(root); err != nil {
+		return fmt.Errorf("Failed to remove dir: [%s], Please check: %w", root, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(root), 0755); err != nil {
+		return fmt.Errorf("Failed to make dir: [%s], Please check: %w", filepath.Dir(root), err)
+	}
+	if err := os.Rename(sp, root); err != nil {
+		return fmt.Errorf("Failed to move [%s] to [%s], Please check: %w", sp, root, err)
+	}
+	return nil
+}
+
+// sweepStaging removes staging directories orphaned by crashed installations,
+// a version whose lock is still held is being installed and left alone
+func (i *UserIsolatedInstaller) sweepStaging() {
+	names, err := os.ReadDir(i.StagingPath)
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		versions, err := os.ReadDir(filepath.Join(i.StagingPath, name.Name()))
+		if err != nil {
+			continue
+		}
+		for _, version := range versions {
+			lock, err := filelock.TryAcquire(i.lockFile(name.Name(), version.Name()))
+			if err != nil {
+				continue
+			}
+			vsp := filepath.Join(i.StagingPath, name.Name(), version.Name())
+			log.Debug().Msgf("Sweeping the orphaned staging dir: %s", vsp)
+			_ = os.RemoveAll(vsp)
+			_ = lock.Release()
 		}
 	}
-	return st, i.done(st.Info(), df)
 }
 
 func (i *UserIsolatedInstaller) Link(names ...string) error {
@@ -130,18 +294,54 @@ func (i *UserIsolatedInstaller) GetSdk(name string) (Sdk, error) {
 }
 
 func (i *UserIsolatedInstaller) GetVersion(sdk Sdk) (string, error) {
-	if v := os.Getenv(fmt.Sprintf("XVM_%s_VERSION", strings.ToUpper(sdk.Info().Name))); v != "" {
-		return strings.TrimPrefix(v, "v"), nil
+	v, err := i.DetectVersion(sdk)
+	if err != nil {
+		return "", err
 	}
-	v, err := sdk.Version()
+	return strings.TrimPrefix(v.Version, "v"), nil
+}
+
+// DetectVersion returns the version defined for the current directory, XVM_{SDK}_VERSION takes precedence
+// over the files of the project, the sources checked are recorded as well
+func (i *UserIsolatedInstaller) DetectVersion(sdk Sdk) (*tools.Version, error) {
+	env := VersionEnv(sdk)
+	if ev := os.Getenv(env); ev != "" {
+		v := &tools.Version{}
+		v.Check("env."+env, ev)
+		return v, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	v, err := sdk.Version(wd)
+	if err != nil {
+		return nil, err
+	}
+	v.Candidates = append([]*tools.Candidate{{Source: "env." + env}}, v.Candidates...)
+	return v, nil
+}
+
+// VersionEnv returns the environment variable defining the version of the sdk, such as XVM_GO_VERSION
+func VersionEnv(sdk Sdk) string {
+	return fmt.Sprintf("XVM_%s_VERSION", strings.ToUpper(sdk.Info().Name))
+}
+
+// ProjectVersion returns the version defined by the files of the project at dir, ignoring the environment
+func (i *UserIsolatedInstaller) ProjectVersion(sdk Sdk, dir string) (string, error) {
+	v, err := sdk.Version(dir)
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimPrefix(v, "v"), nil
+	return strings.TrimPrefix(v.Version, "v"), nil
 }
 
 func (i *UserIsolatedInstaller) LatestVersion(sdk Sdk) (*mirrors.VersionDesc, error) {
-	versions, err := sdk.Info().Mirror.Versions()
+	return latestVersion(sdk.Info().Mirror)
+}
+
+func latestVersion(mirror mirrors.Mirror) (*mirrors.VersionDesc, error) {
+	versions, err := mirror.Versions()
 	if err != nil {
 		return nil, err
 	}
@@ -149,12 +349,17 @@ func (i *UserIsolatedInstaller) LatestVersion(sdk Sdk) (*mirrors.VersionDesc, er
 		return nil, errors.New("no version found for thecurrent machine")
 	}
 	return slices.MaxFunc(versions, func(a, b *mirrors.VersionDesc) int {
-		return semver.Compare(a.Version, b.Version)
+		return constraint.Compare(a.Version, b.Version)
 	}), nil
 }
 
 func (s *SdkTool) VersionDesc(ver string) (*mirrors.VersionDesc, error) {
-	versions, err := s.Sdk.Info().Mirror.Versions()
+	return versionDesc(s.Sdk, ver)
+}
+
+func versionDesc(sdk Sdk, ver string) (*mirrors.VersionDesc, error) {
+	qualifier, ver := Qualify(sdk, ver)
+	versions, err := MirrorOf(sdk, qualifier).Versions()
 	if err != nil {
 		return nil, err
 	}
@@ -174,21 +379,105 @@ func (s *SdkTool) Info() *SdkInfo {
 	return s.Sdk.Info()
 }
 
-func (s *SdkTool) Run(ctx context.Context, args ...string) error {
+// Envs returns the environment variables of the sdk, followed by the ones declared by the .xvm.toml manifests
+func (s *SdkTool) Envs() ([]string, error) {
+	var envs []string
+	if ie := s.Info().WithEnvs; ie != nil {
+		envs = append(envs, ie(s.Root)...)
+	}
+	m := s.Manifest
+	if m == nil {
+		return envs, nil
+	}
+	keys := make([]string, 0, len(m.Env))
+	for k := range m.Env {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		envs = append(envs, k+"="+m.Env[k])
+	}
+	return envs, nil
+}
+
+// Prepare runs the PreRun hook of the sdk, records the usage of the version and returns the environment variables
+// the tools are run with
+func (s *SdkTool) Prepare() ([]string, error) {
 	if preRun := s.Info().PreRun; preRun != nil {
 		if err := preRun(s.Root); err != nil {
-			return err
+			return nil, err
 		}
 	}
+	project := ""
+	if s.Defined {
+		project = currentProject()
+	}
+	if err := recordUsage(s.Root, project); err != nil {
+		log.Debug().Msgf("Failed to record the usage of %s: %s", s.Root, err)
+	}
+	return s.Envs()
+}
+
+// Run runs the tool with the arguments. On unix the xvm process is replaced by the tool, so that the tool receives
+// the signals and exits as if it was run directly, and ctx is ignored. Otherwise, such as on windows or with
+// XVM_NO_EXEC=true, the tool is run as a child process, which is forwarded the signals received by xvm
+func (s *SdkTool) Run(ctx context.Context, args ...string) error {
+	envs, err := s.Prepare()
+	if err != nil {
+		return err
+	}
 	tp := filepath.Join(s.Root, s.Tool.Path)
-	tc := exec.CommandContext(ctx, tp, args...)
-	if ie := s.Sdk.Info().WithEnvs; ie != nil {
-		tc.Env = append(os.Environ(), s.Info().WithEnvs(s.Root)...)
+	env := mergeEnv(os.Environ(), envs)
+	if canExec() {
+		return execTool(tp, args, env)
 	}
+	return runChild(ctx, tp, args, env)
+}
+
+// runChild runs the tool as a child process, the exit code of which is returned as an *exec.ExitError,
+// xvm is killed by the same signal if the child is killed by one
+func runChild(ctx context.Context, path string, args, env []string) error {
+	tc := exec.CommandContext(ctx, path, args...)
+	tc.Env = env
 	tc.Stdin = os.Stdin
 	tc.Stdout = os.Stdout
 	tc.Stderr = os.Stderr
-	return tc.Run()
+	// the signals received while starting are forwarded once the child is started
+	sigs := make(chan os.Signal, len(forwardedSignals))
+	signal.Notify(sigs, forwardedSignals...)
+	if err := tc.Start(); err != nil {
+		signal.Stop(sigs)
+		return err
+	}
+	// a signal is always forwarded, even if the terminal has sent it to the child as well,
+	// since xvm cannot tell who sent it
+	go func() {
+		for sig := range sigs {
+			_ = tc.Process.Signal(sig)
+		}
+	}()
+	err := tc.Wait()
+	signal.Stop(sigs)
+	close(sigs)
+	propagateSignal(tc.ProcessState)
+	return err
+}
+
+// mergeEnv overrides the environment variables of env by the ones of overrides, the later value of a duplicated
+// variable wins, since unlike exec.Cmd, the exec system call does not remove the duplicated ones
+func mergeEnv(env, overrides []string) []string {
+	merged := make([]string, 0, len(env)+len(overrides))
+	index := map[string]int{}
+	for _, kv := range append(slices.Clone(env), overrides...) {
+		key, _, _ := strings.Cut(kv, "=")
+		if i, ok := index[key]; ok {
+			merged[i] = kv
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, kv)
+	}
+	return merged
 }
 
 func (i *UserIsolatedInstaller) link(name string) error {
@@ -212,6 +501,7 @@ func (i *UserIsolatedInstaller) getSdkTool(name string) (*SdkTool, error) {
 	var names []string
 	for _, sdk := range i.Sdks {
 		if st := findSdkTool(sdk, name); st != nil {
+			st.Manifest = i.Manifest
 			return st, nil
 		}
 		names = append(names, sdkToolNames(sdk)...)
@@ -237,7 +527,14 @@ func sdkToolNames(sdk Sdk) []string {
 	return names
 }
 
-func (i *UserIsolatedInstaller) downloadAndExtracting(url string, path string) error {
+// skipChecksumEnv lets the archives without a published checksum be installed unverified
+const skipChecksumEnv = "XVM_SKIP_CHECKSUM"
+
+func skipChecksum() bool {
+	return strings.ToLower(os.Getenv(skipChecksumEnv)) == "true"
+}
+
+func (i *UserIsolatedInstaller) downloadAndExtracting(info *SdkInfo, url, sha256, path string) error {
 	temp, err := os.MkdirTemp("", "")
 	if err != nil {
 		return fmt.Errorf("Failed to make temp dir: %w", err)
@@ -246,27 +543,45 @@ func (i *UserIsolatedInstaller) downloadAndExtracting(url string, path string) e
 		_ = os.RemoveAll(temp)
 	}()
 	log.Info().Msgf("Downloading %s ...", url)
-	filename, err := tools.Download(url, temp)
+	filename, err := tools.Download(url, temp, i.Progress)
 	if err != nil {
 		return fmt.Errorf("Failed to download: %w", err)
 	}
+	if sha256 == "" {
+		if !skipChecksum() {
+			return fmt.Errorf("no checksum is published for %s, set %s=true to install it without verifying", url, skipChecksumEnv)
+		}
+		log.Warn().Msgf("No checksum is published for %s, skip verifying as %s is set", url, skipChecksumEnv)
+	} else if err := tools.VerifySha256(filename, sha256); err != nil {
+		return err
+	}
 	log.Info().Msgf("Extracting to %s ...", path)
-	err = tools.Unarchive(filename, path)
+	extract := info.Extract
+	if extract == nil {
+		extract = tools.Unarchive
+	}
+	err = extract(filename, path)
 	if err != nil {
 		return fmt.Errorf("Failed to extracting: %w", err)
 	}
 	return nil
 }
 
-func (i *UserIsolatedInstaller) done(d *SdkInfo, df string) error {
-	f, err := os.OpenFile(df, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
+// lock serializes the installation of the sdk version across processes
+func (i *UserIsolatedInstaller) lock(name, version string) (*filelock.Lock, error) {
+	lp := i.lockFile(name, version)
+	lock, err := filelock.Acquire(lp, func(owner *filelock.Owner) {
+		log.Info().Msgf("Waiting for another xvm process (%s) to finish installing %s@v%s ...", owner, name, version)
+	})
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("Failed to lock: [%s], Please check: %w", lp, err)
 	}
-	data, err := json.Marshal(d)
-	if err != nil {
-		return err
+	if lock.Stale != nil {
+		log.Warn().Msgf("Recovered the lock of %s@v%s left by a killed xvm process (%s)", name, version, lock.Stale)
 	}
-	_, err = f.Write(data)
-	return err
+	return lock, nil
+}
+
+func (i *UserIsolatedInstaller) lockFile(name, version string) string {
+	return filepath.Join(i.LockPath, name, version+".lock")
 }
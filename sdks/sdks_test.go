@@ -0,0 +1,49 @@
+package sdks
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeEnv(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		env       []string
+		overrides []string
+		want      []string
+	}{
+		{
+			name:      "override",
+			env:       []string{"PATH=/usr/bin", "HOME=/root"},
+			overrides: []string{"PATH=/sdk/bin:/usr/bin", "GOROOT=/sdk"},
+			want:      []string{"PATH=/sdk/bin:/usr/bin", "HOME=/root", "GOROOT=/sdk"},
+		},
+		{
+			name: "duplicated in env",
+			env:  []string{"A=1", "B=2", "A=3"},
+			want: []string{"A=3", "B=2"},
+		},
+		{
+			name:      "duplicated in overrides",
+			env:       []string{"A=1"},
+			overrides: []string{"A=2", "B=1", "A=3"},
+			want:      []string{"A=3", "B=1"},
+		},
+		{
+			name:      "empty and bare",
+			env:       []string{"A=1", "B"},
+			overrides: []string{"A=", "B=2"},
+			want:      []string{"A=", "B=2"},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			env := slices.Clone(c.env)
+			if got := mergeEnv(env, c.overrides); !slices.Equal(got, c.want) {
+				t.Errorf("mergeEnv(%q, %q) = %q, want %q", c.env, c.overrides, got, c.want)
+			}
+			if !slices.Equal(env, c.env) {
+				t.Errorf("mergeEnv modified env: %q, want %q", env, c.env)
+			}
+		})
+	}
+}
@@ -9,12 +9,18 @@ import (
 
 var ErrUnsupported = errors.New("unsupported archiver")
 
+// Unarchive extracts the archive into path without its top-level directory
 func Unarchive(filename, path string) error {
+	return UnarchiveStrip(filename, path, 1)
+}
+
+// UnarchiveStrip extracts the archive into path, stripping the given number of leading path components
+func UnarchiveStrip(filename, path string, stripComponents int) error {
 	iua, err := archiver.ByExtension(filename)
 	if err != nil {
 		return ErrUnsupported
 	}
-	applyArchiverSettings(iua, 1)
+	applyArchiverSettings(iua, int64(stripComponents))
 	u, ok := iua.(archiver.Unarchiver)
 	if ok {
 		return u.Unarchive(filename, path)
@@ -25,6 +25,17 @@ func AddUserPath(paths ...string) error {
 	return nil
 }
 
+// MissingUserPaths returns the rc file of the current terminal and the paths not added to it
+func MissingUserPaths(paths ...string) (string, []string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", nil, err
+	}
+	rc := filepath.Join(home, getCurrentTerminal().Rc())
+	dps, err := filterNewPaths(rc, paths...)
+	return rc, dps, err
+}
+
 type terminal interface {
 	Name() string
 	Rc() string
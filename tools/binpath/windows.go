@@ -26,6 +26,23 @@ func AddUserPath(paths ...string) error {
 	return addXvmToEnvPath()
 }
 
+// MissingUserPaths returns the registry key of the user's environment and the paths not added to it
+func MissingUserPaths(paths ...string) (string, []string, error) {
+	key := `HKEY_CURRENT_USER\` + env
+	op, err := getValue(path)
+	if err != nil {
+		return key, nil, fmt.Errorf(`failed to get value: %s, %w`, path, err)
+	}
+	if !strings.Contains(op, fmt.Sprintf("%%%s%%", xvm)) {
+		return key, paths, nil
+	}
+	xp, err := getValue(xvm)
+	if err != nil {
+		return key, nil, fmt.Errorf(`failed to get value: %s, %w`, xvm, err)
+	}
+	return key, filterNewPaths(xp, paths...), nil
+}
+
 func setToXvmPath(paths []string) error {
 	if len(paths) == 0 {
 		return nil
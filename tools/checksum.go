@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ChecksumError is returned when the digest of a downloaded file differs from the trusted one
+type ChecksumError struct {
+	Filename string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s, expected sha256: %s, actual: %s", e.Filename, e.Expected, e.Actual)
+}
+
+// Sha256 returns the hex encoded sha256 digest of the file
+func Sha256(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifySha256 checks the file against the expected sha256 digest, a *ChecksumError is returned on mismatch
+func VerifySha256(filename, expected string) error {
+	actual, err := Sha256(filename)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return &ChecksumError{Filename: filename, Expected: expected, Actual: actual}
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package constraint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type operator int
+
+const (
+	prefix operator = iota
+	equal
+	greaterOrEqual
+	less
+)
+
+type comparator struct {
+	op     operator
+	parts  []int
+	negate bool
+}
+
+// Constraint is a set of versions described by a version spec, supported specs are:
+//
+//	1.21.3, 1.21, 18, 1.21.x    versions starting with the given components
+//	^18.2                       >=18.2.0 <19.0.0
+//	~1.21.3                     >=1.21.3 <1.22.0
+//	>=17 <21, >17, <=20         comparisons, separated by spaces or commas
+//	^16 || ^18                  any of the alternatives
+//	*, x                        any version
+//	==3.11.*, ~=3.10, !=3.9.1   the python flavor, see PEP 440
+type Constraint struct {
+	spec  string
+	exact bool
+	anyOf [][]comparator
+}
+
+// Parse parses the version spec
+func Parse(spec string) (*Constraint, error) {
+	c := &Constraint{spec: strings.TrimSpace(spec)}
+	if c.spec == "" {
+		return nil, fmt.Errorf("empty version spec")
+	}
+	for _, alternative := range strings.Split(c.spec, "||") {
+		var (
+			all      []comparator
+			operator string
+		)
+		for _, field := range strings.FieldsFunc(alternative, isSeparator) {
+			// an operator separated from its version, such as >= 17
+			if op, rest := splitOperator(field); op != "" && rest == "" {
+				operator += op
+				continue
+			}
+			cs, err := parseComparator(operator + field)
+			operator = ""
+			if err != nil {
+				return nil, fmt.Errorf("invalid version spec: %s, %w", spec, err)
+			}
+			all = append(all, cs...)
+		}
+		if len(all) == 0 || operator != "" {
+			return nil, fmt.Errorf("invalid version spec: %s", spec)
+		}
+		c.anyOf = append(c.anyOf, all)
+	}
+	if len(c.anyOf) == 1 && len(c.anyOf[0]) == 1 {
+		only := c.anyOf[0][0]
+		c.exact = only.op == prefix && len(only.parts) >= 3 && isPlain(c.spec)
+	}
+	return c, nil
+}
+
+// IsExact reports whether the spec names a complete version rather than a range
+func (c *Constraint) IsExact() bool {
+	return c.exact
+}
+
+func (c *Constraint) String() string {
+	return c.spec
+}
+
+// Check reports whether the version satisfies the constraint
+func (c *Constraint) Check(version string) bool {
+	v, _, err := parseVersion(version)
+	if err != nil {
+		return false
+	}
+	for _, all := range c.anyOf {
+		if checkAll(all, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkAll(all []comparator, v []int) bool {
+	for _, c := range all {
+		if !c.check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c comparator) check(v []int) bool {
+	return c.test(v) != c.negate
+}
+
+func (c comparator) test(v []int) bool {
+	switch c.op {
+	case equal:
+		return compareParts(v, c.parts) == 0
+	case greaterOrEqual:
+		return compareParts(v, c.parts) >= 0
+	case less:
+		return compareParts(v, c.parts) < 0
+	default:
+		if len(v) < len(c.parts) {
+			return compareParts(v, c.parts) == 0
+		}
+		return compareParts(v[:len(c.parts)], c.parts) == 0
+	}
+}
+
+func parseComparator(field string) ([]comparator, error) {
+	op, rest := splitOperator(field)
+	if op == "" && (rest == "*" || rest == "x" || rest == "X") {
+		return []comparator{{op: prefix}}, nil
+	}
+	parts, err := parsePartial(rest)
+	if err != nil {
+		return nil, err
+	}
+	wildcard := strings.HasSuffix(rest, "*")
+	switch op {
+	case "==", "===", "!=":
+		// ==3.11 is 3.11.0 only, unlike ==3.11.*
+		c := comparator{op: equal, parts: parts, negate: op == "!="}
+		if wildcard {
+			c.op = prefix
+		}
+		return []comparator{c}, nil
+	case "~=":
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("~= requires at least two components: %s", rest)
+		}
+		return []comparator{{op: greaterOrEqual, parts: parts}, {op: less, parts: bump(parts, len(parts)-2)}}, nil
+	case "", "=":
+		return []comparator{{op: prefix, parts: parts}}, nil
+	case "^":
+		return []comparator{{op: greaterOrEqual, parts: parts}, {op: less, parts: bumpCaret(parts)}}, nil
+	case "~":
+		return []comparator{{op: greaterOrEqual, parts: parts}, {op: less, parts: bumpTilde(parts)}}, nil
+	case ">=":
+		return []comparator{{op: greaterOrEqual, parts: parts}}, nil
+	case ">":
+		// >1.21 excludes every 1.21 patch
+		return []comparator{{op: greaterOrEqual, parts: bump(parts, len(parts)-1)}}, nil
+	case "<":
+		return []comparator{{op: less, parts: parts}}, nil
+	case "<=":
+		// <=20 includes every 20 release
+		return []comparator{{op: less, parts: bump(parts, len(parts)-1)}}, nil
+	}
+	return nil, fmt.Errorf("unknown operator: %s", op)
+}
+
+func splitOperator(field string) (string, string) {
+	for _, op := range []string{"===", "==", "~=", "!=", ">=", "<=", "=", ">", "<", "^", "~"} {
+		if rest, ok := strings.CutPrefix(field, op); ok {
+			return op, strings.TrimSpace(rest)
+		}
+	}
+	return "", field
+}
+
+// parsePartial parses versions like 1.21, 18 or 1.21.x, wildcards end the version
+func parsePartial(s string) ([]int, error) {
+	s = strings.TrimPrefix(s, "v")
+	if s == "" {
+		return nil, fmt.Errorf("missing version")
+	}
+	var parts []int
+	for _, field := range strings.Split(s, ".") {
+		if field == "x" || field == "X" || field == "*" {
+			break
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version: %s", s)
+		}
+		parts = append(parts, n)
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("invalid version: %s", s)
+	}
+	return parts, nil
+}
+
+// bumpCaret returns the upper bound of ^parts, which allows changes not modifying the left-most non-zero component
+func bumpCaret(parts []int) []int {
+	for i, p := range parts {
+		if p != 0 || i == len(parts)-1 {
+			return bump(parts, i)
+		}
+	}
+	return bump(parts, len(parts)-1)
+}
+
+// bumpTilde returns the upper bound of ~parts, which allows patch changes if a minor version is specified
+func bumpTilde(parts []int) []int {
+	if len(parts) == 1 {
+		return bump(parts, 0)
+	}
+	return bump(parts, 1)
+}
+
+// bump increases the i-th component and drops the following ones
+func bump(parts []int, i int) []int {
+	bumped := make([]int, i+1)
+	copy(bumped, parts[:i+1])
+	bumped[i]++
+	return bumped
+}
+
+func isSeparator(r rune) bool {
+	return r == ' ' || r == '\t' || r == ','
+}
+
+func isPlain(spec string) bool {
+	return !strings.ContainsAny(spec, "xX* ,|<>=!^~")
+}
+
+// Compare compares two versions component by component, such as 17.0.8.1 and 17.0.9,
+// a pre-release is less than the release, invalid versions are less than valid ones
+func Compare(a, b string) int {
+	av, apre, aerr := parseVersion(a)
+	bv, bpre, berr := parseVersion(b)
+	switch {
+	case aerr != nil && berr != nil:
+		return 0
+	case aerr != nil:
+		return -1
+	case berr != nil:
+		return 1
+	}
+	if r := compareParts(av, bv); r != 0 {
+		return r
+	}
+	switch {
+	case apre == bpre:
+		return 0
+	case apre == "":
+		return 1
+	case bpre == "":
+		return -1
+	}
+	return strings.Compare(apre, bpre)
+}
+
+func compareParts(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseVersion parses a complete version like v1.21.3 or 17.0.8.1-beta into its components and pre-release
+func parseVersion(version string) ([]int, string, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, pre, _ := strings.Cut(version, "-")
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid version: %s", version)
+		}
+		parts = append(parts, n)
+	}
+	return parts, pre, nil
+}
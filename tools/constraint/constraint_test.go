@@ -0,0 +1,160 @@
+package constraint
+
+import "testing"
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		spec    string
+		version string
+		want    bool
+	}{
+		// partial versions match by whole components
+		{"1.2", "1.2.0", true},
+		{"1.2", "1.2.9", true},
+		{"1.2", "1.20.0", false},
+		{"1.20", "1.2.0", false},
+		{"18", "18.19.1", true},
+		{"18", "180.0.0", false},
+		{"1.21.x", "1.21.5", true},
+		{"1.21.*", "1.22.0", false},
+		{"v1.21.3", "1.21.3", true},
+		{"1.21.3", "1.21.4", false},
+		{"*", "0.0.1", true},
+		{"x", "21.0.2", true},
+		// caret
+		{"^18.2", "18.2.0", true},
+		{"^18.2", "18.99.0", true},
+		{"^18.2", "19.0.0", false},
+		{"^18.2", "18.1.9", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.2", "0.2.0", true},
+		{"^0.2", "0.3.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^0", "0.9.9", true},
+		{"^0", "1.0.0", false},
+		// tilde
+		{"~1.21.3", "1.21.9", true},
+		{"~1.21.3", "1.22.0", false},
+		{"~1", "1.9.0", true},
+		{"~1", "2.0.0", false},
+		// comparisons bump the partial versions
+		{">1.21", "1.21.9", false},
+		{">1.21", "1.22.0", true},
+		{">1.21.3", "1.21.4", true},
+		{">17", "17.0.9", false},
+		{">17", "18.0.0", true},
+		{"<=20", "20.11.1", true},
+		{"<=20", "21.0.0", false},
+		{"<=1.21", "1.21.9", true},
+		{"<=1.21", "1.22.0", false},
+		{"<21", "20.99.0", true},
+		{"<21", "21.0.0", false},
+		{">=17 <21", "17.0.0", true},
+		{">=17 <21", "21.0.1", false},
+		{">=17, <21", "20.0.2", true},
+		{">= 17", "17.0.1", true},
+		{"=1.21", "1.21.5", true},
+		// alternatives
+		{"^16 || ^18", "16.20.2", true},
+		{"^16 || ^18", "18.19.0", true},
+		{"^16 || ^18", "17.9.1", false},
+		{"<3.9||>=3.11", "3.10.4", false},
+		{"<3.9||>=3.11", "3.11.0", true},
+		// PEP 440
+		{"~=3.10", "3.10.0", true},
+		{"~=3.10", "3.99.0", true},
+		{"~=3.10", "4.0.0", false},
+		{"~=3.10", "3.9.9", false},
+		{"~=3.10.2", "3.10.9", true},
+		{"~=3.10.2", "3.11.0", false},
+		{"==3.11.*", "3.11.7", true},
+		{"==3.11.*", "3.12.0", false},
+		{"==3.11", "3.11.0", true},
+		{"==3.11", "3.11.7", false},
+		{"===3.11.7", "3.11.7", true},
+		{"!=3.9.1", "3.9.1", false},
+		{"!=3.9.1", "3.9.2", true},
+		{">=3.8,!=3.9.*", "3.9.4", false},
+		{">=3.8,!=3.9.*", "3.10.0", true},
+		// invalid versions never match
+		{"*", "latest", false},
+	}
+	for _, tt := range tests {
+		c, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %s", tt.spec, err)
+			continue
+		}
+		if got := c.Check(tt.version); got != tt.want {
+			t.Errorf("Parse(%q).Check(%q) = %v, want %v", tt.spec, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"", " ", "abc", "1.a", ">=", "^", "~=3", "1.21 ||", "-1", ">=17 <"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestIsExact(t *testing.T) {
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{"1.21.3", true},
+		{"v1.21.3", true},
+		{"17.0.8.1", true},
+		{" 1.21.3 ", true},
+		{"1.21", false},
+		{"18", false},
+		{"1.21.x", false},
+		{"1.21.*", false},
+		{"=1.21.3", false},
+		{"==3.11.7", false},
+		{"^1.21.3", false},
+		{"~1.21.3", false},
+		{">=1.21.3", false},
+		{"1.21.3 || 1.21.4", false},
+		{"*", false},
+	}
+	for _, tt := range tests {
+		c, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %s", tt.spec, err)
+			continue
+		}
+		if got := c.IsExact(); got != tt.want {
+			t.Errorf("Parse(%q).IsExact() = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "1.20.0", -1},
+		{"1.21.10", "1.21.9", 1},
+		{"v1.21.3", "1.21.3", 0},
+		{"17.0.8.1", "17.0.9", -1},
+		{"17.0.8.1", "17.0.8", 1},
+		{"21", "21.0.0", 0},
+		{"21.0.0-beta", "21.0.0", -1},
+		{"21.0.0-beta", "21.0.0-alpha", 1},
+		{"1.0.0+build.1", "1.0.0", 0},
+		{"latest", "1.0.0", -1},
+		{"1.0.0", "latest", 1},
+		{"latest", "stable", 0},
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
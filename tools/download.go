@@ -14,9 +14,11 @@ import (
 
 const contentDisposition = "Content-Disposition"
 
-func Download(url string, path string) (string, error) {
+// Download downloads the url into the directory path and returns the downloaded file,
+// the progress is reported to a bar of its own unless a shared progress is given
+func Download(url string, path string, progress *Progress) (string, error) {
 	fp := filepath.Join(path, getFilename(url))
-	resp, err := download(url, fp)
+	resp, err := download(url, fp, progress)
 	if err != nil {
 		return "", err
 	}
@@ -39,21 +41,35 @@ func getFilename(url string) string {
 	return filename
 }
 
-func download(url, filename string) (*http.Response, error) {
-	resp, _ := http.Get(url)
+func download(url, filename string, progress *Progress) (*http.Response, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
 
 	if err := os.MkdirAll(filepath.Dir(filename), os.ModeDir); err != nil {
 		return nil, err
 	}
-	f, _ := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY, 0644)
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
 	defer f.Close()
 
-	bar := progressbar.DefaultBytes(
-		resp.ContentLength,
-		"downloading",
-	)
-	_, err := io.Copy(io.MultiWriter(f, bar), resp.Body)
+	var bar io.Writer = progress
+	if progress == nil {
+		bar = progressbar.DefaultBytes(
+			resp.ContentLength,
+			"downloading",
+		)
+	} else {
+		progress.Track(resp.ContentLength)
+	}
+	_, err = io.Copy(io.MultiWriter(f, bar), resp.Body)
 	return resp, err
 }
 
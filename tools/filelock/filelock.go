@@ -0,0 +1,121 @@
+package filelock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const pollInterval = 200 * time.Millisecond
+
+// ErrLocked is returned by TryAcquire when the lock is held by another process
+var ErrLocked = errors.New("locked by another process")
+
+// Owner describes the process holding a lock
+type Owner struct {
+	Pid      int       `json:"pid"`
+	Hostname string    `json:"hostname"`
+	Since    time.Time `json:"since"`
+}
+
+func (o *Owner) String() string {
+	if o == nil {
+		return "unknown process"
+	}
+	return fmt.Sprintf("pid %d on %s since %s", o.Pid, o.Hostname, o.Since.Format(time.DateTime))
+}
+
+// Lock is an exclusive lock on a file shared by all xvm processes,
+// the operating system releases it when the holding process dies
+type Lock struct {
+	f *os.File
+	// Stale is the previous owner if it died without releasing the lock
+	Stale *Owner
+}
+
+// Acquire blocks until the lock of path is held by the current process,
+// wait is called once with the current owner if the lock has to be waited for
+func Acquire(path string, wait func(owner *Owner)) (*Lock, error) {
+	waited := false
+	for {
+		l, err := TryAcquire(path)
+		if !errors.Is(err, ErrLocked) {
+			return l, err
+		}
+		if !waited && wait != nil {
+			wait(ReadOwner(path))
+		}
+		waited = true
+		time.Sleep(pollInterval)
+	}
+}
+
+// TryAcquire acquires the lock of path without blocking, ErrLocked is returned if it is held by another process
+func TryAcquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	l := &Lock{f: f, Stale: ReadOwner(path)}
+	if err := l.writeOwner(); err != nil {
+		_ = l.Release()
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Lock) writeOwner() error {
+	hostname, _ := os.Hostname()
+	data, err := json.Marshal(&Owner{Pid: os.Getpid(), Hostname: hostname, Since: time.Now()})
+	if err != nil {
+		return err
+	}
+	if err := l.f.Truncate(0); err != nil {
+		return err
+	}
+	_, err = l.f.WriteAt(data, 0)
+	return err
+}
+
+// Release releases the lock, the lock file itself is kept to avoid racing with other processes
+func (l *Lock) Release() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	_ = l.f.Truncate(0)
+	err := unlock(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	l.f = nil
+	return err
+}
+
+// ReadOwner returns the owner recorded in the lock file of path, nil if unknown
+func ReadOwner(path string) *Owner {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	var owner Owner
+	if err := json.Unmarshal(data, &owner); err != nil {
+		return nil
+	}
+	return &owner
+}
@@ -14,6 +14,13 @@ import (
 
 const fileMode = 0744
 
+const (
+	cmdPrefix   = "@echo off\n\n"
+	cmdSuffix   = " %*"
+	shellPrefix = "#!/bin/sh\n\n"
+	shellSuffix = ` "$@"`
+)
+
 type Option int
 
 const (
@@ -40,6 +47,27 @@ func New(name, bin, command string, options ...Option) (string, error) {
 	return binPath, linkToMsys2(name, bin, command, option)
 }
 
+// Command returns the linked file of `name` under directory bin and the command it calls,
+// an error is returned if the file is missing or not written by New
+func Command(name, bin string) (string, string, error) {
+	file, prefix, suffix := filepath.Join(bin, name), shellPrefix, shellSuffix
+	if runtime.GOOS == "windows" {
+		file, prefix, suffix = cmdFile(bin, name), cmdPrefix, cmdSuffix
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return file, "", err
+	}
+	command, ok := strings.CutPrefix(string(data), prefix)
+	if ok {
+		command, ok = strings.CutSuffix(command, suffix)
+	}
+	if !ok {
+		return file, "", fmt.Errorf("unrecognized file: %s, which is not linked by xvm", file)
+	}
+	return file, command, nil
+}
+
 func applyOptions(options []Option) Option {
 	option := None
 	for _, o := range options {
@@ -111,11 +139,11 @@ func cmdFile(binPath, name string) string {
 }
 
 func cmdTemplate(command string) []byte {
-	return []byte(fmt.Sprintf("@echo off\n\n%s %%*", command))
+	return []byte(cmdPrefix + command + cmdSuffix)
 }
 
 func shellTemplate(command string) []byte {
-	return []byte(fmt.Sprintf("#!/bin/sh\n\n%s \"$@\"", command))
+	return []byte(shellPrefix + command + shellSuffix)
 }
 
 func msys2File(binPath, name string) string {
@@ -0,0 +1,143 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/modern-devops/xvm/tools"
+
+	"github.com/BurntSushi/toml"
+	"github.com/rs/zerolog/log"
+)
+
+// File is the per-project manifest of xvm
+const File = ".xvm.toml"
+
+// Manifest declares the versions, settings and environment variables of the sdks for a project
+type Manifest struct {
+	// Versions maps the names of the sdks to their version specs
+	Versions map[string]string `toml:"versions"`
+	Java     struct {
+		Distribution string `toml:"distribution"`
+	} `toml:"java"`
+	// Mirrors maps the names of the sdks to their mirror overrides, such as mirror and api
+	Mirrors map[string]map[string]string `toml:"mirrors"`
+	// Env is injected into the environment of every sdk command
+	Env map[string]string `toml:"env"`
+}
+
+// Parse parses the manifest named name
+func Parse(name string, data []byte) (*Manifest, error) {
+	var m Manifest
+	if _, err := toml.Decode(string(data), &m); err != nil {
+		return nil, fmt.Errorf("parse %s failed, %w", name, err)
+	}
+	for k := range m.Env {
+		if !tools.ValidEnvName(k) {
+			return nil, fmt.Errorf("parse %s failed, invalid env name: %s", name, k)
+		}
+	}
+	return &m, nil
+}
+
+// Load merges the manifests found for the working directory wd, the settings of the nearest one win,
+// the env and mirrors of a manifest are dropped unless trusted reports true for its file, which is asked
+// only for the manifests declaring either, every manifest is trusted if trusted is nil
+func Load(wd string, trusted func(name string) bool) (*Manifest, error) {
+	dirs, err := tools.VersionDirs(wd)
+	if err != nil {
+		return nil, err
+	}
+	merged := &Manifest{
+		Versions: map[string]string{},
+		Mirrors:  map[string]map[string]string{},
+		Env:      map[string]string{},
+	}
+	// the farthest first, so that the nearer ones override it
+	for i := len(dirs) - 1; i >= 0; i-- {
+		name := filepath.Join(dirs[i], File)
+		data, err := os.ReadFile(name)
+		if err != nil {
+			// an unreadable manifest of a parent directory must not break every tool run below it
+			if !os.IsNotExist(err) {
+				log.Debug().Msgf("Skip the manifest %s: %s", name, err)
+			}
+			continue
+		}
+		m, err := Parse(name, data)
+		if err != nil {
+			return nil, err
+		}
+		// the env runs code through variables like LD_PRELOAD, and the mirrors pick both the archives and their checksums
+		if (len(m.Env) > 0 || len(m.Mirrors) > 0) && trusted != nil && !trusted(name) {
+			m.Env, m.Mirrors = nil, nil
+		}
+		merged.merge(m)
+	}
+	return merged, nil
+}
+
+// Files returns the manifests found for the working directory wd, the nearest first
+func Files(wd string) ([]string, error) {
+	dirs, err := tools.VersionDirs(wd)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, dir := range dirs {
+		name := filepath.Join(dir, File)
+		if _, err := os.Stat(name); err == nil {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+func (m *Manifest) merge(o *Manifest) {
+	for k, v := range o.Versions {
+		m.Versions[k] = v
+	}
+	if o.Java.Distribution != "" {
+		m.Java.Distribution = o.Java.Distribution
+	}
+	for sdk, overrides := range o.Mirrors {
+		if m.Mirrors[sdk] == nil {
+			m.Mirrors[sdk] = map[string]string{}
+		}
+		for k, v := range overrides {
+			m.Mirrors[sdk][k] = v
+		}
+	}
+	for k, v := range o.Env {
+		m.Env[k] = v
+	}
+}
+
+// VersionFile returns the manifest as a version file of the sdk, which is tried before the native ones
+func VersionFile(sdk string) *tools.VersionFile {
+	return &tools.VersionFile{
+		Name: File,
+		Parse: func(data []byte) (string, error) {
+			m, err := Parse(File, data)
+			if err != nil {
+				return "", err
+			}
+			return m.Versions[sdk], nil
+		},
+	}
+}
+
+// DistributionFile returns the manifest as a version file of the java distribution
+func DistributionFile() *tools.VersionFile {
+	return &tools.VersionFile{
+		Name: File,
+		Parse: func(data []byte) (string, error) {
+			m, err := Parse(File, data)
+			if err != nil {
+				return "", err
+			}
+			return m.Java.Distribution, nil
+		},
+	}
+}
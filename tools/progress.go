@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"sync"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// Progress is a progress bar shared by concurrent downloads
+type Progress struct {
+	mu          sync.Mutex
+	description string
+	bar         *progressbar.ProgressBar
+	total       int64
+}
+
+func NewProgress(description string) *Progress {
+	return &Progress{description: description}
+}
+
+// Track adds a download of size bytes to the progress, unknown sizes are ignored
+func (p *Progress) Track(size int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// the bar is shown on the first download, nothing is shown if everything is installed
+	if p.bar == nil {
+		p.bar = progressbar.DefaultBytes(1, p.description)
+	}
+	if size <= 0 {
+		return
+	}
+	p.total += size
+	// keep one byte outstanding, so that the bar is not completed before all downloads are tracked
+	p.bar.ChangeMax64(p.total + 1)
+}
+
+func (p *Progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.bar.Write(b)
+}
+
+// Finish completes the progress once all downloads are done
+func (p *Progress) Finish() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.bar == nil {
+		return nil
+	}
+	p.bar.ChangeMax64(p.total)
+	return p.bar.Finish()
+}
@@ -4,9 +4,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
 )
 
+// envName matches the names of environment variables that can be exported by every shell
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidEnvName reports whether the name is a valid name of an environment variable, which is safe to print
+// in the commands evaluated by shells
+func ValidEnvName(name string) bool {
+	return envName.MatchString(name)
+}
+
 func GitRootPath(wd string) string {
 	cp := wd
 	for {
@@ -22,22 +33,49 @@ func GitRootPath(wd string) string {
 	return wd
 }
 
-func DetectVersionFiles(vf string) ([]string, error) {
-	wd, err := os.Getwd()
-	if err != nil {
-		return nil, err
-	}
-	var vfs []string
-	vfs = append(vfs, filepath.Join(wd, vf))
-	if rp := GitRootPath(wd); wd != rp && rp != "" {
-		vfs = append(vfs, filepath.Join(rp, vf))
+// DetectFiles returns the candidates of the project file, such as go.mod, in wd and its parent directories
+func DetectFiles(wd, name string) []string {
+	dirs := ParentDirs(wd)
+	fs := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		fs = append(fs, filepath.Join(dir, name))
 	}
+	return fs
+}
+
+// VersionDirs returns the directories searched for the version files of the working directory wd, the nearest first,
+// which are the parent directories of wd and then the user's home as the fallback
+func VersionDirs(wd string) ([]string, error) {
+	dirs := ParentDirs(wd)
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, err
 	}
-	vfs = append(vfs, filepath.Join(home, vf))
-	return vfs, nil
+	if !slices.Contains(dirs, home) {
+		dirs = append(dirs, home)
+	}
+	return dirs, nil
+}
+
+// ParentDirs returns wd and its parent directories up to the filesystem root, the nearest first,
+// the walk stops below the directories listed by XVM_CEILING_DIRECTORIES like GIT_CEILING_DIRECTORIES of git,
+// wd itself is always returned even if it is listed
+func ParentDirs(wd string) []string {
+	ceilings := filepath.SplitList(os.Getenv("XVM_CEILING_DIRECTORIES"))
+	for i, c := range ceilings {
+		ceilings[i] = filepath.Clean(c)
+	}
+	var dirs []string
+	for cp := filepath.Clean(wd); ; {
+		dirs = append(dirs, cp)
+		lp := filepath.Dir(cp)
+		// a ceiling is never passed, even if it is wd itself
+		if lp == cp || slices.Contains(ceilings, cp) || slices.Contains(ceilings, lp) {
+			break
+		}
+		cp = lp
+	}
+	return dirs
 }
 
 func IsWindows() bool {
@@ -50,3 +88,22 @@ func CommandFile(command string) string {
 	}
 	return command
 }
+
+// WriteFileAtomic replaces the file as a whole by writing a temp file in the same directory and renaming it,
+// so that other processes reading or writing it at the same time never see a partial file
+func WriteFileAtomic(name string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
@@ -0,0 +1,101 @@
+package trust
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/modern-devops/xvm/tools"
+)
+
+// Store keeps the files trusted by the user along with the sha256 digests of their contents,
+// so that a file is no longer trusted once it is changed, like `direnv allow`
+type Store struct {
+	Path string
+}
+
+// Trusted reports whether the current content of the file is trusted
+func (s *Store) Trusted(file string) bool {
+	digests, err := s.load()
+	if err != nil {
+		return false
+	}
+	digest, err := fileDigest(file)
+	if err != nil {
+		return false
+	}
+	return digests[absPath(file)] == digest
+}
+
+// Allow trusts the current contents of the files
+func (s *Store) Allow(files ...string) error {
+	digests, err := s.load()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		digest, err := fileDigest(file)
+		if err != nil {
+			return err
+		}
+		digests[absPath(file)] = digest
+	}
+	return s.save(digests)
+}
+
+// Deny revokes the trust of the files
+func (s *Store) Deny(files ...string) error {
+	digests, err := s.load()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		delete(digests, absPath(file))
+	}
+	return s.save(digests)
+}
+
+func (s *Store) load() (map[string]string, error) {
+	digests := map[string]string{}
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return digests, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &digests); err != nil {
+		return nil, fmt.Errorf("parse %s failed, %w", s.Path, err)
+	}
+	return digests, nil
+}
+
+func (s *Store) save(digests map[string]string) error {
+	data, err := json.MarshalIndent(digests, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return err
+	}
+	return tools.WriteFileAtomic(s.Path, data)
+}
+
+func fileDigest(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func absPath(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return file
+}
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ToolVersionsFile is the version file of asdf, which pins the versions of many tools
+const ToolVersionsFile = ".tool-versions"
+
+// asdfNames maps the names of the sdks to the names of the asdf plugins, the same if unmapped
+var asdfNames = map[string]string{
+	"go":   "golang",
+	"node": "nodejs",
+}
+
+// VersionFile is a file defining the version of a sdk
+type VersionFile struct {
+	Name string
+	// Parse returns the version defined by the content of the file, empty if it defines none,
+	// the first line that is neither blank nor a comment is the version if nil
+	Parse func(data []byte) (string, error)
+}
+
+// ToolVersions returns the .tool-versions version file of the sdk
+func ToolVersions(sdk string) *VersionFile {
+	name := sdk
+	if n, ok := asdfNames[sdk]; ok {
+		name = n
+	}
+	return &VersionFile{
+		Name: ToolVersionsFile,
+		Parse: func(data []byte) (string, error) {
+			return parseToolVersions(data, name), nil
+		},
+	}
+}
+
+// Version is a detected version and where it comes from
+type Version struct {
+	// Version is empty if no source defines one
+	Version string
+	// Source is where the version is defined, such as the path of the version file
+	Source string
+	// Candidates are the sources checked in order of precedence, the last one is the source if found
+	Candidates []*Candidate
+}
+
+// Candidate is a source checked for the version
+type Candidate struct {
+	Source string
+	// Found reports whether the source exists
+	Found bool
+	// Version is the version defined by the source, empty if it defines none
+	Version string
+}
+
+// Check records the source with the version it defines, which is used if it is the first one defining a version,
+// and reports whether the source defines one
+func (v *Version) Check(source, version string) bool {
+	v.Candidates = append(v.Candidates, &Candidate{Source: source, Found: true, Version: version})
+	if version == "" || v.Version != "" {
+		return version != ""
+	}
+	v.Version, v.Source = version, source
+	return true
+}
+
+// Miss records the source that does not exist
+func (v *Version) Miss(source string) {
+	v.Candidates = append(v.Candidates, &Candidate{Source: source})
+}
+
+// DetectVersion returns the version defined by the version files for the working directory wd, the directories
+// are searched in the order of VersionDirs, and the files are tried in the given order in each directory
+func DetectVersion(wd string, vfs ...*VersionFile) (*Version, error) {
+	version := &Version{}
+	dirs, err := VersionDirs(wd)
+	if err != nil {
+		return version, err
+	}
+	for _, dir := range dirs {
+		for _, vf := range vfs {
+			name := filepath.Join(dir, vf.Name)
+			data, err := os.ReadFile(name)
+			if err != nil {
+				// an unreadable file, such as a directory or one without permission, is skipped like a missing one
+				if !os.IsNotExist(err) {
+					log.Debug().Msgf("Skip the version file %s: %s", name, err)
+				}
+				version.Miss(name)
+				continue
+			}
+			parse := vf.Parse
+			if parse == nil {
+				parse = ParseVersionLine
+			}
+			v, err := parse(data)
+			if err != nil {
+				return version, err
+			}
+			if version.Check(name, v) {
+				return version, nil
+			}
+		}
+	}
+	return version, nil
+}
+
+// ParseVersionLine returns the first line of the content that is neither blank nor a comment
+func ParseVersionLine(data []byte) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return line, nil
+	}
+	return "", scanner.Err()
+}
+
+// parseToolVersions returns the first version of the tool in the lines of `<tool> <version>...`,
+// the versions not managed by xvm, such as system, ref:<ref> and path:<path>, are ignored
+func parseToolVersions(data []byte, tool string) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != tool {
+			continue
+		}
+		v := fields[1]
+		if v == "system" || strings.HasPrefix(v, "ref:") || strings.HasPrefix(v, "path:") {
+			return ""
+		}
+		return v
+	}
+	return ""
+}
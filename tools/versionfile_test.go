@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParentDirsCeiling(t *testing.T) {
+	root := t.TempDir()
+	wd := filepath.Join(root, "a", "b")
+	tests := []struct {
+		ceilings []string
+		want     []string
+	}{
+		{[]string{root}, []string{wd, filepath.Join(root, "a")}},
+		{[]string{filepath.Join(root, "a")}, []string{wd}},
+		// the working directory is searched even if it is a ceiling
+		{[]string{wd}, []string{wd}},
+		{[]string{wd, filepath.Join(root, "a")}, []string{wd}},
+	}
+	for _, tt := range tests {
+		t.Setenv("XVM_CEILING_DIRECTORIES", strings.Join(tt.ceilings, string(os.PathListSeparator)))
+		if got := ParentDirs(wd); !slices.Equal(got, tt.want) {
+			t.Errorf("ParentDirs with the ceilings %v = %v, want %v", tt.ceilings, got, tt.want)
+		}
+	}
+}
+
+func TestDetectVersionSkipsUnreadableFiles(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HOME", root)
+	t.Setenv("XVM_CEILING_DIRECTORIES", filepath.Dir(root))
+	wd := filepath.Join(root, "project", "app")
+	// a directory named like the version file cannot be read
+	if err := os.MkdirAll(filepath.Join(wd, ".goversion"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "project", ".goversion"), []byte("1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := DetectVersion(wd, &VersionFile{Name: ".goversion"})
+	if err != nil {
+		t.Fatalf("DetectVersion failed: %s", err)
+	}
+	if v.Version != "1.21" || v.Source != filepath.Join(root, "project", ".goversion") {
+		t.Errorf("DetectVersion = %q of %s, want 1.21 of the parent directory", v.Version, v.Source)
+	}
+}